@@ -0,0 +1,171 @@
+/* alloc_track.go - allocation site tracking and leak reports */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, skip frames of internal callers
+*/
+/*
+DESCRIPTION
+    With Options.TrackAllocations, the stack of caller is recorded for each
+    chunk allocated by Get()/GetContext()/ChunkCache.Get(). Stacks are
+    interned, so chunks allocated at the same site share one allocSite.
+
+    LeakReport() groups live chunks (with refs > 0) by allocation site.
+    Chunks which stay in a report for a long time are likely leaked.
+
+Usage:
+    slabPool, err := CreateSlabPoolWithOptions(4096, 128, 1024, 2,
+            &Options{TrackAllocations: true})
+    for _, site := range slabPool.LeakReport() {
+        log.Printf("%d chunks, %d bytes allocated at:\n%s",
+                   site.Chunks, site.Bytes, site)
+    }
+*/
+package slab_pool
+
+import (
+    "bytes"
+    "fmt"
+    "runtime"
+    "sort"
+    "sync"
+)
+
+const (
+    TRACK_STACK_DEPTH = 32 // max frames recorded for allocation site
+)
+
+// stack of allocation site
+type allocSite struct {
+    pcs [TRACK_STACK_DEPTH]uintptr // program counters (zero padded)
+}
+
+// sites interned by stack
+type siteTable struct {
+    sites sync.Map // [TRACK_STACK_DEPTH]uintptr => *allocSite
+}
+
+// LeakSite is a group of live chunks allocated at the same site
+type LeakSite struct {
+    Stack  []runtime.Frame // stack of allocation site (caller first)
+    Chunks int             // count of live chunks
+    Bytes  int64           // bytes of live chunks (size of chunks in slab)
+}
+
+// record stack of caller, 'skip' is the count of frames to skip (0 for
+// caller of record)
+func (t *siteTable) record(skip int) *allocSite {
+    var pcs [TRACK_STACK_DEPTH]uintptr
+    runtime.Callers(skip+2, pcs[:])
+    if site, ok := t.sites.Load(pcs); ok {
+        return site.(*allocSite)
+    }
+    site, _ := t.sites.LoadOrStore(pcs, &allocSite{pcs: pcs})
+    return site.(*allocSite)
+}
+
+// record allocation site of chunk, called for allocation only. 'skip' is
+// the count of frames to skip above caller of trackAlloc (0 if called by
+// the exported method directly)
+func (sp *SlabPool) trackAlloc(chunk []byte, skip int) {
+    slab, chunkIndex, err := sp.locate(chunk)
+    if err != nil {
+        return
+    }
+    // skip trackAlloc(), its caller and the frames above
+    site := sp.sites.record(2 + skip)
+    slab.chunkInfo[chunkIndex].site.Store(site)
+}
+
+/* LeakReport - group live chunks by allocation site
+ *
+ * Return:
+ *     - sites of live chunks, sorted by bytes in descending order (nil if
+ *       TrackAllocations is not enabled)
+ */
+func (sp *SlabPool) LeakReport() []LeakSite {
+    if !sp.options.TrackAllocations {
+        return nil
+    }
+
+    leaks := make(map[*allocSite]*LeakSite)
+    collect := func(slab *Slab) {
+        for i := range slab.chunkInfo {
+            info := &slab.chunkInfo[i]
+            if info.getRef() <= 0 {
+                continue
+            }
+            site := info.site.Load()
+            leak := leaks[site]
+            if leak == nil {
+                leak = new(LeakSite)
+                leaks[site] = leak
+            }
+            leak.Chunks++
+            leak.Bytes += int64(slab.chunkSize)
+        }
+    }
+
+    // chunks in slab classes
+    for _, slabClass := range sp.slabClasses {
+        slabClass.lock()
+        for _, slab := range slabClass.slabs {
+            collect(slab)
+        }
+        slabClass.unlock()
+    }
+
+    // chunks from Go heap
+    sp.table.slabs.Range(func(key, value interface{}) bool {
+        if slab := value.(*Slab); slab.slabClass == nil {
+            collect(slab)
+        }
+        return true
+    })
+
+    report := make([]LeakSite, 0, len(leaks))
+    for site, leak := range leaks {
+        if site != nil {
+            leak.Stack = site.frames()
+        }
+        report = append(report, *leak)
+    }
+    sort.Slice(report, func(i, j int) bool {
+        return report[i].Bytes > report[j].Bytes
+    })
+    return report
+}
+
+// frames of allocation site
+func (s *allocSite) frames() []runtime.Frame {
+    n := 0
+    for n < len(s.pcs) && s.pcs[n] != 0 {
+        n++
+    }
+    if n == 0 {
+        return nil
+    }
+    frames := make([]runtime.Frame, 0, n)
+    iter := runtime.CallersFrames(s.pcs[:n])
+    for {
+        frame, more := iter.Next()
+        frames = append(frames, frame)
+        if !more {
+            break
+        }
+    }
+    return frames
+}
+
+// String returns the stack of allocation site, in the format of panics
+func (s LeakSite) String() string {
+    if len(s.Stack) == 0 {
+        return "unknown\n"
+    }
+    var buf bytes.Buffer
+    for _, frame := range s.Stack {
+        fmt.Fprintf(&buf, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+    }
+    return buf.String()
+}
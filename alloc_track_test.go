@@ -0,0 +1,96 @@
+/* alloc_track_test.go - unit test for alloc_track.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "strings"
+    "testing"
+)
+
+// allocate chunks at site A
+func allocSiteA(sp *SlabPool, count int) [][]byte {
+    chunks := make([][]byte, 0, count)
+    for i := 0; i < count; i++ {
+        chunk, _ := sp.Get(1000)
+        chunks = append(chunks, chunk)
+    }
+    return chunks
+}
+
+// allocate chunk at site B
+func allocSiteB(sp *SlabPool) []byte {
+    chunk, _ := sp.Get(100)
+    return chunk
+}
+
+func TestLeakReport(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2,
+                                             &Options{TrackAllocations: true})
+    chunks := allocSiteA(slabPool, 3)
+    chunk := allocSiteB(slabPool)
+
+    report := slabPool.LeakReport()
+    if len(report) != 2 {
+        t.Fatalf("should report 2 sites, got %d", len(report))
+    }
+    if report[0].Chunks != 3 || report[0].Bytes != 3*1024 ||
+       !strings.HasSuffix(report[0].Stack[0].Function, "allocSiteA") {
+        t.Errorf("wrong site A: %d chunks, %d bytes\n%s",
+                 report[0].Chunks, report[0].Bytes, report[0])
+    }
+    if report[1].Chunks != 1 || report[1].Bytes != 128 ||
+       !strings.HasSuffix(report[1].Stack[0].Function, "allocSiteB") {
+        t.Errorf("wrong site B: %d chunks, %d bytes\n%s",
+                 report[1].Chunks, report[1].Bytes, report[1])
+    }
+    if !strings.Contains(report[1].String(), "alloc_track_test.go") {
+        t.Errorf("stack should contain file of allocation site:\n%s", report[1])
+    }
+
+    // chunks released are not reported
+    slabPool.Put(chunk)
+    slabPool.Put(chunks[0])
+    report = slabPool.LeakReport()
+    if len(report) != 1 || report[0].Chunks != 2 {
+        t.Errorf("should report 2 chunks at site A")
+    }
+    slabPool.Put(chunks[1])
+    slabPool.Put(chunks[2])
+    if report = slabPool.LeakReport(); len(report) != 0 {
+        t.Errorf("should report nothing, got %d sites", len(report))
+    }
+}
+
+func TestLeakReportDisabled(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 128, 1024, 2)
+    slabPool.Get(100)
+    if report := slabPool.LeakReport(); report != nil {
+        t.Errorf("should report nothing without TrackAllocations")
+    }
+}
+
+func TestLeakReportCacheAndHeap(t *testing.T) {
+    options := &Options{TrackAllocations: true, MaxSlabsPerClass: 1,
+                        ExhaustPolicy: EXHAUST_HEAP}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 1024, 1024, 2, options)
+    cache := slabPool.NewChunkCache(&CacheOptions{Depth: 4, Batch: 4})
+
+    // 4 chunks from slab, and 1 chunk from Go heap
+    for i := 0; i < 5; i++ {
+        cache.Get(1000)
+    }
+    report := slabPool.LeakReport()
+    if len(report) != 1 || report[0].Chunks != 5 || report[0].Bytes != 4*1024+1000 {
+        t.Fatalf("should report 5 chunks at one site")
+    }
+    if !strings.HasSuffix(report[0].Stack[0].Function, "TestLeakReportCacheAndHeap") {
+        t.Errorf("wrong allocation site:\n%s", report[0])
+    }
+}
@@ -0,0 +1,250 @@
+/* buffer.go - growable buffer backed by SlabPool */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, track allocation site at caller of Buffer
+*/
+/*
+DESCRIPTION
+    Buffer is a variable-sized buffer of bytes like bytes.Buffer, while its
+    storage is always a chunk from SlabPool. It takes the whole chunk of a
+    slab class, and grows by Grow() of pool, i.e. moving into a chunk of a
+    larger slab class. Sizes above chunkSizeMax need Options.LargeAlloc.
+
+    Buffer implements io.Reader, io.Writer, io.ByteReader, io.ByteWriter,
+    io.ReaderFrom and io.WriterTo. Its chunk is returned to pool by Reset()
+    or Release(). Like bytes.Buffer, it is not safe for concurrent use.
+
+Usage:
+    buf := slabPool.NewBuffer()
+    buf.WriteString("hello")
+    buf.ReadFrom(conn)
+    ...
+    buf.Release()
+*/
+package slab_pool
+
+import (
+    "io"
+)
+
+const (
+    BUFFER_MIN_READ = 512 // min free space for each Read() in ReadFrom()
+)
+
+type Buffer struct {
+    pool  *SlabPool
+    chunk []byte // chunk from pool (nil if no chunk)
+    off   int    // read at chunk[off]
+    end   int    // write at chunk[end]
+}
+
+/* NewBuffer - create buffer backed by slab pool
+ *
+ * Return:
+ *     - buffer: empty buffer (no chunk allocated until written)
+ */
+func (sp *SlabPool) NewBuffer() *Buffer {
+    b := new(Buffer)
+    b.pool = sp
+    return b
+}
+
+// Len returns count of unread bytes
+func (b *Buffer) Len() int {
+    return b.end - b.off
+}
+
+// Cap returns capacity of chunk of buffer
+func (b *Buffer) Cap() int {
+    return len(b.chunk)
+}
+
+// Bytes returns unread bytes, valid until next modification of buffer
+func (b *Buffer) Bytes() []byte {
+    if b.chunk == nil {
+        return nil
+    }
+    return b.chunk[b.off:b.end]
+}
+
+// String returns unread bytes as string
+func (b *Buffer) String() string {
+    return string(b.Bytes())
+}
+
+/* Grow - grow capacity of buffer for another n bytes
+ *
+ * Params:
+ *     - n: count of bytes to write
+ *
+ * Return:
+ *     - err: error from pool (e.g. ErrPoolExhausted)
+ */
+func (b *Buffer) Grow(n int) error {
+    return b.grow(n)
+}
+
+// make room for 'n' more bytes after unread bytes, must be called by
+// exported methods of Buffer directly
+func (b *Buffer) grow(n int) error {
+    size := b.Len() + n
+    if size <= len(b.chunk)-b.off {
+        return nil
+    }
+
+    // slide unread bytes to the beginning
+    if b.off > 0 {
+        copy(b.chunk, b.chunk[b.off:b.end])
+        b.end -= b.off
+        b.off = 0
+    }
+    if size <= len(b.chunk) {
+        return nil
+    }
+
+    // double capacity, but no more than chunkSizeMax if not needed
+    newSize := 2 * len(b.chunk)
+    if newSize < size {
+        newSize = size
+    }
+    if newSize > b.pool.chunkSizeMax && size <= b.pool.chunkSizeMax {
+        newSize = b.pool.chunkSizeMax
+    }
+    if slabClass := b.pool.slabClassFor(newSize); slabClass != nil {
+        newSize = slabClass.chunkSize
+    }
+
+    // skip the exported method of Buffer for allocation site
+    var chunk []byte
+    var err error
+    if b.chunk == nil {
+        chunk, err = b.pool.getTracked(newSize, 1)
+    } else {
+        chunk, err = b.pool.grow(b.chunk, newSize, 1)
+    }
+    if err != nil {
+        return err
+    }
+    b.chunk = chunk
+    return nil
+}
+
+// Write appends p to buffer (io.Writer)
+func (b *Buffer) Write(p []byte) (int, error) {
+    if err := b.grow(len(p)); err != nil {
+        return 0, err
+    }
+    n := copy(b.chunk[b.end:], p)
+    b.end += n
+    return n, nil
+}
+
+// WriteString appends s to buffer
+func (b *Buffer) WriteString(s string) (int, error) {
+    if err := b.grow(len(s)); err != nil {
+        return 0, err
+    }
+    n := copy(b.chunk[b.end:], s)
+    b.end += n
+    return n, nil
+}
+
+// WriteByte appends c to buffer (io.ByteWriter)
+func (b *Buffer) WriteByte(c byte) error {
+    if err := b.grow(1); err != nil {
+        return err
+    }
+    b.chunk[b.end] = c
+    b.end++
+    return nil
+}
+
+// Read reads unread bytes into p (io.Reader), io.EOF if buffer is empty
+func (b *Buffer) Read(p []byte) (int, error) {
+    if b.Len() == 0 {
+        b.off, b.end = 0, 0
+        if len(p) == 0 {
+            return 0, nil
+        }
+        return 0, io.EOF
+    }
+    n := copy(p, b.chunk[b.off:b.end])
+    b.off += n
+    return n, nil
+}
+
+// ReadByte reads next byte (io.ByteReader), io.EOF if buffer is empty
+func (b *Buffer) ReadByte() (byte, error) {
+    if b.Len() == 0 {
+        b.off, b.end = 0, 0
+        return 0, io.EOF
+    }
+    c := b.chunk[b.off]
+    b.off++
+    return c, nil
+}
+
+// ReadFrom reads from r until io.EOF and appends to buffer (io.ReaderFrom)
+func (b *Buffer) ReadFrom(r io.Reader) (int64, error) {
+    var total int64
+    for {
+        // read into the free space left, if buffer could not grow
+        if err := b.grow(BUFFER_MIN_READ); err != nil && b.end == len(b.chunk) {
+            return total, err
+        }
+        n, err := r.Read(b.chunk[b.end:])
+        if n < 0 {
+            panic("slab_pool.Buffer: reader returned negative count from Read")
+        }
+        b.end += n
+        total += int64(n)
+        if err == io.EOF {
+            return total, nil
+        }
+        if err != nil {
+            return total, err
+        }
+    }
+}
+
+// WriteTo writes unread bytes to w (io.WriterTo)
+func (b *Buffer) WriteTo(w io.Writer) (int64, error) {
+    if b.Len() == 0 {
+        return 0, nil
+    }
+    n, err := w.Write(b.chunk[b.off:b.end])
+    b.off += n
+    if err == nil && b.Len() > 0 {
+        err = io.ErrShortWrite
+    }
+    if b.Len() == 0 {
+        b.off, b.end = 0, 0
+    }
+    return int64(n), err
+}
+
+// Reset empties buffer, and returns its chunk to pool. Buffer could be
+// written again after Reset().
+func (b *Buffer) Reset() {
+    b.Release()
+}
+
+/* Release - return chunk of buffer to pool
+ *
+ * Return:
+ *     - err: error of Put()
+ *
+ * Note:
+ *     Slices returned by Bytes() must not be used after Release()
+ */
+func (b *Buffer) Release() error {
+    chunk := b.chunk
+    b.chunk = nil
+    b.off, b.end = 0, 0
+    if chunk == nil {
+        return nil
+    }
+    return b.pool.Put(chunk)
+}
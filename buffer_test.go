@@ -0,0 +1,187 @@
+/* buffer_test.go - unit test for buffer.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "bytes"
+    "errors"
+    "io"
+    "strings"
+    "testing"
+)
+
+// Buffer implements io interfaces
+var (
+    _ io.Reader     = (*Buffer)(nil)
+    _ io.Writer     = (*Buffer)(nil)
+    _ io.ByteReader = (*Buffer)(nil)
+    _ io.ByteWriter = (*Buffer)(nil)
+    _ io.ReaderFrom = (*Buffer)(nil)
+    _ io.WriterTo   = (*Buffer)(nil)
+)
+
+func TestBufferWriteAndRead(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 128, 1024, 2)
+    buf := slabPool.NewBuffer()
+
+    buf.WriteString("hello")
+    buf.WriteByte(' ')
+    buf.Write([]byte("world"))
+    if buf.String() != "hello world" || buf.Len() != 11 || buf.Cap() != 128 {
+        t.Errorf("wrong buffer: %q, cap %d", buf.String(), buf.Cap())
+    }
+
+    p := make([]byte, 6)
+    if n, err := buf.Read(p); n != 6 || err != nil || string(p) != "hello " {
+        t.Errorf("wrong read: %q, %v", p[:n], err)
+    }
+    if c, err := buf.ReadByte(); c != 'w' || err != nil {
+        t.Errorf("wrong byte read: %c, %v", c, err)
+    }
+    rest, _ := io.ReadAll(buf)
+    if string(rest) != "orld" {
+        t.Errorf("wrong read: %q", rest)
+    }
+    if _, err := buf.Read(p); err != io.EOF {
+        t.Errorf("expected io.EOF, got %v", err)
+    }
+    if _, err := buf.ReadByte(); err != io.EOF {
+        t.Errorf("expected io.EOF, got %v", err)
+    }
+
+    if err := buf.Release(); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if stats := slabPool.Stats(); stats.ChunksInUse != 0 {
+        t.Errorf("chunk should be returned to pool: %+v", stats)
+    }
+}
+
+func TestBufferGrow(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 128, 1024, 2)
+    buf := slabPool.NewBuffer()
+
+    data := bytes.Repeat([]byte("0123456789"), 100)
+    for i := 0; i < len(data); i += 10 {
+        if _, err := buf.Write(data[i : i+10]); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+    }
+    if !bytes.Equal(buf.Bytes(), data) || buf.Cap() != 1024 {
+        t.Errorf("wrong buffer after grown, cap %d", buf.Cap())
+    }
+    stats := slabPool.Stats()
+    if stats.ChunksInUse != 1 || stats.Classes[3].ChunksInUse != 1 {
+        t.Errorf("buffer should be moved to class of 1024: %+v", stats)
+    }
+
+    // unread bytes slide to the beginning instead of growing
+    buf.Read(make([]byte, 900))
+    if _, err := buf.Write(data[:900]); err != nil || buf.Cap() != 1024 {
+        t.Errorf("buffer should not grow: %v, cap %d", err, buf.Cap())
+    }
+    if !bytes.Equal(buf.Bytes(), append(data[900:], data[:900]...)) {
+        t.Errorf("wrong buffer after slided")
+    }
+
+    // beyond chunkSizeMax
+    if _, err := buf.Write(data); err == nil {
+        t.Errorf("should fail to grow beyond chunkSizeMax")
+    }
+    if buf.Len() != 1000 {
+        t.Errorf("buffer should be kept on error")
+    }
+
+    buf.Reset()
+    if buf.Len() != 0 || slabPool.Stats().ChunksInUse != 0 {
+        t.Errorf("chunk should be returned to pool by Reset()")
+    }
+    buf.WriteString("again")
+    if buf.String() != "again" {
+        t.Errorf("buffer should be written after Reset()")
+    }
+    buf.Release()
+}
+
+func TestBufferLarge(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2, &Options{LargeAlloc: true})
+    buf := slabPool.NewBuffer()
+
+    data := bytes.Repeat([]byte("x"), 10000)
+    if n, err := buf.Write(data); n != len(data) || err != nil {
+        t.Fatalf("should write large data: %v", err)
+    }
+    if stats := slabPool.Stats(); stats.LargeChunks != 1 {
+        t.Errorf("buffer should be a large chunk: %+v", stats)
+    }
+    buf.Release()
+    if stats := slabPool.Stats(); stats.LargeChunks != 0 {
+        t.Errorf("large chunk should be released: %+v", stats)
+    }
+}
+
+func TestBufferReadFromAndWriteTo(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 128, 4096, 2)
+    buf := slabPool.NewBuffer()
+
+    text := strings.Repeat("slab pool ", 300)
+    n, err := buf.ReadFrom(strings.NewReader(text))
+    if n != int64(len(text)) || err != nil || buf.String() != text {
+        t.Errorf("wrong ReadFrom(): %d, %v", n, err)
+    }
+
+    var out bytes.Buffer
+    n, err = buf.WriteTo(&out)
+    if n != int64(len(text)) || err != nil || out.String() != text || buf.Len() != 0 {
+        t.Errorf("wrong WriteTo(): %d, %v", n, err)
+    }
+
+    // reader larger than chunkSizeMax, the buffer is filled
+    n, err = buf.ReadFrom(strings.NewReader(text + text))
+    if n != 4096 || err == nil {
+        t.Errorf("ReadFrom() should fill buffer then fail: %d, %v", n, err)
+    }
+    buf.Release()
+
+    // error of reader
+    readErr := errors.New("read error")
+    if _, err := buf.ReadFrom(&errReader{readErr}); err != readErr {
+        t.Errorf("expected error of reader, got %v", err)
+    }
+    buf.Release()
+}
+
+func TestBufferTrackAllocations(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2,
+                                            &Options{TrackAllocations: true})
+    buf := slabPool.NewBuffer()
+
+    // allocation site is caller of Buffer, for new chunk and chunk moved
+    for _, size := range []int{10, 500} {
+        buf.Write(bytes.Repeat([]byte("x"), size))
+        report := slabPool.LeakReport()
+        if len(report) != 1 || len(report[0].Stack) == 0 {
+            t.Fatalf("should report one site: %v", report)
+        }
+        if fn := report[0].Stack[0].Function; !strings.HasSuffix(fn, ".TestBufferTrackAllocations") {
+            t.Errorf("allocation site should be caller of Buffer, got %s", fn)
+        }
+    }
+    buf.Release()
+}
+
+// reader always returning error
+type errReader struct {
+    err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+    return 0, r.err
+}
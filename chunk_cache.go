@@ -0,0 +1,217 @@
+/* chunk_cache.go - per-goroutine chunk cache in front of SlabClass */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, check chunks in debug mode
+2026/10/17, by agent, track allocation sites
+2026/10/17, by agent, add statistics
+2026/10/17, by agent, record sizes requested
+2026/10/17, by agent, return error for size without slab class
+2026/10/17, by agent, allocate large chunks from pool
+2026/10/17, by agent, hand off chunks put to waiters of GetContext()
+2026/10/17, by agent, follow exhaust policy for large chunks
+*/
+/*
+DESCRIPTION
+    ChunkCache keeps a magazine of free chunks for each SlabClass. Chunks
+    are moved between a magazine and its SlabClass in batches, so most of
+    Get()/Put() on the cache do not touch shared state of the pool.
+
+    A ChunkCache is owned by one goroutine and must not be used by
+    multiple goroutines at the same time. Chunks in magazines are still
+    allocated from the view of slabs, call Flush() to return them to the
+    pool when the cache is no longer used.
+
+Usage:
+    cache := slabPool.NewChunkCache(&CacheOptions{Depth: 128, Batch: 32})
+    defer cache.Flush()
+
+    chunk, err := cache.Get(500)
+    err = cache.Put(chunk)
+*/
+package slab_pool
+
+import (
+    "errors"
+    "fmt"
+)
+
+const (
+    CACHE_DEPTH_DEFAULT = 64 // default max chunks per magazine
+)
+
+type CacheOptions struct {
+    // Depth is the max number of free chunks kept for each SlabClass.
+    // When a magazine is full, Put() flushes Batch chunks to the pool.
+    Depth int
+
+    // Batch is the number of chunks moved between a magazine and its
+    // SlabClass at a time. It is limited to Depth.
+    Batch int
+}
+
+type ChunkCache struct {
+    pool      *SlabPool
+    depth     int          // max chunks per magazine
+    batch     int          // chunks moved between magazine and SlabClass
+    magazines [][]chunkRef // free chunks for each SlabClass
+}
+
+/* NewChunkCache - create chunk cache for slab pool
+ *
+ * Params:
+ *     - options: options for chunk cache (nil for default options)
+ *
+ * Return:
+ *     - cache  : chunk cache
+ */
+func (sp *SlabPool) NewChunkCache(options *CacheOptions) *ChunkCache {
+    c := new(ChunkCache)
+    c.pool = sp
+    c.depth = CACHE_DEPTH_DEFAULT
+    if options != nil && options.Depth > 0 {
+        c.depth = options.Depth
+    }
+    c.batch = c.depth / 2
+    if options != nil && options.Batch > 0 {
+        c.batch = options.Batch
+    }
+    if c.batch > c.depth {
+        c.batch = c.depth
+    }
+    if c.batch <= 0 {
+        c.batch = 1
+    }
+    c.magazines = make([][]chunkRef, len(sp.slabClasses))
+    return c
+}
+
+/* Get - allocate a chunk with length 'size'
+ *
+ * Params:
+ *     - size: chunk size
+ *
+ * Return:
+ *     - chunk: chunk allocated
+ *     - err  : error
+ */
+func (c *ChunkCache) Get(size int) ([]byte, error) {
+    if c.pool.sizes != nil {
+        c.pool.sizes.record(size)
+    }
+    if size > c.pool.chunkSizeMax && c.pool.options.LargeAlloc {
+        // large chunk is not cached
+        chunk, err := c.pool.allocate(size)
+        if err == nil && c.pool.sites != nil {
+            c.pool.trackAlloc(chunk, 0)
+        }
+        return chunk, err
+    }
+    if size > c.pool.chunkSizeMax || size <= 0 {
+        return nil, fmt.Errorf("illegal chunk size: %d", size)
+    }
+
+    // refill magazine from slab class
+    i := c.pool.classIndexFor(size)
+    if i >= len(c.magazines) {
+        return nil, fmt.Errorf("no slab class for chunk size: %d", size)
+    }
+    if len(c.magazines[i]) == 0 {
+        refs, err := c.pool.slabClasses[i].chunkAllocBatch(c.batch, c.magazines[i])
+        c.magazines[i] = refs
+        if errors.Is(err, ErrChunkCorrupted) {
+            return nil, fmt.Errorf("Get(): %w", err)
+        }
+        if len(refs) == 0 {
+            // pool is exhausted, follow the exhaust policy of pool
+            chunk, err := c.pool.allocate(size)
+            if err == nil && c.pool.sites != nil {
+                c.pool.trackAlloc(chunk, 0)
+            }
+            return chunk, err
+        }
+    }
+
+    // pop chunk from magazine
+    last := len(c.magazines[i]) - 1
+    ref := c.magazines[i][last]
+    c.magazines[i] = c.magazines[i][:last]
+    if ref.slab.debug {
+        // chunk corrupted in magazine is dropped (quarantined)
+        if err := ref.slab.checkPoison(ref.index); err != nil {
+            return nil, fmt.Errorf("Get(): %w", err)
+        }
+    }
+    ref.slab.chunkInfo[ref.index].setRef(1)
+    ref.slab.slabClass.chunkGot(ref.slab, ref.index, size)
+
+    chunk := ref.slab.chunk(ref.index)[:size]
+    if c.pool.sites != nil {
+        c.pool.trackAlloc(chunk, 0)
+    }
+    return chunk, nil
+}
+
+/* Put - release chunk to chunk cache
+ *
+ * Params:
+ *     - chunk: chunk to release
+ *
+ * Return:
+ *     - err: error
+ */
+func (c *ChunkCache) Put(chunk []byte) error {
+    if err := c.pool.validateChunk(chunk); err != nil {
+        return err
+    }
+
+    // find slab for this chunk
+    slab, chunkIndex, err := c.pool.locate(chunk)
+    if err != nil {
+        return err
+    }
+
+    // chunk from Go heap is not cached
+    slabClass := slab.slabClass
+    if slabClass == nil {
+        return c.pool.Put(chunk)
+    }
+
+    // keep chunk in magazine if no reference any more
+    released, err := slabClass.chunkDecRefKeep(slab, chunkIndex)
+    if err != nil {
+        return err
+    }
+    slabClass.countDecRef(true)
+    if !released {
+        return nil
+    }
+
+    // goroutines waiting in GetContext() are served first
+    if slabClass.hasWaiters() {
+        slabClass.chunkReleaseBatch([]chunkRef{{slab, chunkIndex}})
+        return nil
+    }
+    i := c.pool.classIndexFor(slabClass.chunkSize)
+    c.magazines[i] = append(c.magazines[i], chunkRef{slab, chunkIndex})
+
+    // flush chunks at bottom of magazine if it is full
+    if len(c.magazines[i]) > c.depth {
+        slabClass.chunkReleaseBatch(c.magazines[i][:c.batch])
+        n := copy(c.magazines[i], c.magazines[i][c.batch:])
+        c.magazines[i] = c.magazines[i][:n]
+    }
+    return nil
+}
+
+/* Flush - return all chunks in chunk cache to slab pool */
+func (c *ChunkCache) Flush() {
+    for i, magazine := range c.magazines {
+        if len(magazine) == 0 {
+            continue
+        }
+        c.pool.slabClasses[i].chunkReleaseBatch(magazine)
+        c.magazines[i] = magazine[:0]
+    }
+}
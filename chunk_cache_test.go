@@ -0,0 +1,147 @@
+/* chunk_cache_test.go - unit test for chunk_cache.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "sync"
+    "testing"
+)
+
+func TestChunkCacheGetAndPut(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 64, 1024, 2)
+    cache := slabPool.NewChunkCache(&CacheOptions{Depth: 4, Batch: 2})
+    slabClass := slabPool.slabClassFor(100)
+
+    // refill magazine by batch
+    chunk, err := cache.Get(100)
+    if err != nil || len(chunk) != 100 {
+        t.Errorf("should return valid chunk with size 100")
+    }
+    slab, chunkIndex, _ := slabPool.locate(chunk)
+    if slab.chunkInfo[chunkIndex].refs != 1 {
+        t.Errorf("chunk refs should be 1")
+    }
+    if slab.countFree != slab.countChunk-2 {
+        t.Errorf("count of free chunks should be %d", slab.countChunk-2)
+    }
+
+    // chunk with extra reference is not cached
+    slabPool.IncRef(chunk)
+    cache.Put(chunk)
+    if len(cache.magazines[1]) != 1 || slab.chunkInfo[chunkIndex].refs != 1 {
+        t.Errorf("chunk should not be cached")
+    }
+    cache.Put(chunk)
+    if len(cache.magazines[1]) != 2 || slab.chunkInfo[chunkIndex].refs != 0 {
+        t.Errorf("chunk should be cached")
+    }
+
+    // chunk from another pool
+    slabPool2, _ := CreateSlabPool(4096, 64, 1024, 2)
+    chunk2, _ := slabPool2.Get(100)
+    if err := cache.Put(chunk2); err == nil {
+        t.Errorf("should return error due to wrong input chunk")
+    }
+
+    // flush magazine when it is full
+    chunks := make([][]byte, 0)
+    for i := 0; i < 5; i++ {
+        chunk, _ := cache.Get(100)
+        chunks = append(chunks, chunk)
+    }
+    for _, chunk := range chunks {
+        cache.Put(chunk)
+    }
+    if len(cache.magazines[1]) != 4 {
+        t.Errorf("magazine should be flushed, got %d chunks", len(cache.magazines[1]))
+    }
+
+    // flush all chunks
+    cache.Flush()
+    if len(cache.magazines[1]) != 0 {
+        t.Errorf("magazine should be empty")
+    }
+    if !slabClass.listEmpty(SLAB_USE) || !slabClass.listEmpty(SLAB_FULL) {
+        t.Errorf("all slabs should be free")
+    }
+}
+
+func TestChunkCacheConcurrent(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 64, 1024, 2, &Options{Concurrent: true})
+
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        wg.Add(1)
+        go func(id int) {
+            defer wg.Done()
+            cache := slabPool.NewChunkCache(nil)
+            defer cache.Flush()
+
+            chunks := make([][]byte, 0, 16)
+            for j := 0; j < 2000; j++ {
+                chunk, err := cache.Get(1 + (id*131+j*17)%1024)
+                if err != nil {
+                    t.Errorf("unexpected error: %s", err)
+                    return
+                }
+                chunks = append(chunks, chunk)
+                if len(chunks) == cap(chunks) {
+                    for _, chunk := range chunks {
+                        cache.Put(chunk)
+                    }
+                    chunks = chunks[:0]
+                }
+            }
+            for _, chunk := range chunks {
+                cache.Put(chunk)
+            }
+        }(i)
+    }
+    wg.Wait()
+
+    // all chunks should be released
+    for _, slabClass := range slabPool.slabClasses {
+        if !slabClass.listEmpty(SLAB_USE) || !slabClass.listEmpty(SLAB_FULL) {
+            t.Errorf("all slabs should be free, chunkSize %d", slabClass.chunkSize)
+        }
+    }
+}
+
+func BenchmarkChunkCacheGetAndPut128(b *testing.B) {
+    slabPool, _ := CreateSlabPool(4096, 64, 1024, 2)
+    cache := slabPool.NewChunkCache(nil)
+
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        chunk, _ := cache.Get(128)
+        cache.Put(chunk)
+    }
+}
+
+func TestChunkCacheHeapChunk(t *testing.T) {
+    options := &Options{MaxSlabsPerClass: 1, ExhaustPolicy: EXHAUST_HEAP}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 4096, 4096, 2, options)
+    cache := slabPool.NewChunkCache(nil)
+
+    chunk1, _ := cache.Get(4096)
+    chunk2, err := cache.Get(4096)
+    if err != nil || len(chunk2) != 4096 {
+        t.Errorf("should return chunk from heap")
+    }
+    if err := cache.Put(chunk2); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if err := cache.Put(chunk1); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if len(cache.magazines[0]) != 1 {
+        t.Errorf("only chunk from slab should be cached")
+    }
+}
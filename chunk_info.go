@@ -3,6 +3,10 @@
 modification history
 --------------------
 2014/12/2, by Sijie Yang, create
+2026/10/17, by agent, use atomic reference count
+2026/10/17, by agent, return errors for wrong reference operations
+2026/10/17, by agent, add allocation site
+2026/10/17, by agent, add requested size
 */
 /*
 DESCRIPTION
@@ -10,28 +14,58 @@ DESCRIPTION
 package slab_pool
 
 import (
-    "fmt"
+    "sync/atomic"
+)
+
+const (
+    CHUNK_UNUSED = -1 // reference count of chunk never allocated
 )
 
 type ChunkInfo struct {
-    refs int // reference count
-    next int // next node in the chunk free list
+    refs int32                     // reference count (accessed atomically)
+    size int32                     // size requested for chunk (accessed atomically)
+    next int                       // next node in the chunk free list
+    site atomic.Pointer[allocSite] // allocation site (TrackAllocations only)
 }
 
 // increase reference
-func (c *ChunkInfo) incRef() int {
-    c.refs++
-    if c.refs <= 1 {
-        panic(fmt.Sprintf("incRef(): unexpected reference count %d: %#v", c.refs, c))
+func (c *ChunkInfo) incRef() (int32, error) {
+    for {
+        refs := atomic.LoadInt32(&c.refs)
+        if refs == CHUNK_UNUSED {
+            return refs, ErrNotAllocated
+        }
+        if refs <= 0 {
+            return refs, ErrChunkFreed
+        }
+        if atomic.CompareAndSwapInt32(&c.refs, refs, refs+1) {
+            return refs + 1, nil
+        }
     }
-    return c.refs
 }
 
 // decrease reference
-func (c *ChunkInfo) decRef() int {
-    c.refs--
-    if c.refs < 0 {
-        panic(fmt.Sprintf("decRef(): unexpected reference count %d: %#v", c.refs, c))
+func (c *ChunkInfo) decRef() (int32, error) {
+    for {
+        refs := atomic.LoadInt32(&c.refs)
+        if refs == CHUNK_UNUSED {
+            return refs, ErrNotAllocated
+        }
+        if refs <= 0 {
+            return refs, ErrDoubleFree
+        }
+        if atomic.CompareAndSwapInt32(&c.refs, refs, refs-1) {
+            return refs - 1, nil
+        }
     }
-    return c.refs
+}
+
+// set reference
+func (c *ChunkInfo) setRef(refs int32) {
+    atomic.StoreInt32(&c.refs, refs)
+}
+
+// get reference
+func (c *ChunkInfo) getRef() int32 {
+    return atomic.LoadInt32(&c.refs)
 }
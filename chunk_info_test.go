@@ -34,28 +34,27 @@ func TestChunkInfo(t *testing.T) {
     }
 }
 
-func TestIncRefPanic(t *testing.T) {
-    noPanic := false
-    defer func() {
-        if recover() != nil && noPanic {
-            t.Errorf("should panic when refs is wrong")
-        }
-    }()
-
+func TestIncRefError(t *testing.T) {
     chunkInfo := new(ChunkInfo)
-    chunkInfo.incRef()
-    noPanic = true
+    if _, err := chunkInfo.incRef(); err != ErrChunkFreed {
+        t.Errorf("should return ErrChunkFreed, got %v", err)
+    }
+    chunkInfo.refs = CHUNK_UNUSED
+    if _, err := chunkInfo.incRef(); err != ErrNotAllocated {
+        t.Errorf("should return ErrNotAllocated, got %v", err)
+    }
 }
 
-func TestDecRefPanic(t *testing.T) {
-    noPanic := false
-    defer func() {
-        if recover() != nil && noPanic {
-            t.Errorf("should panic when refs is wrong")
-        }
-    }()
-
+func TestDecRefError(t *testing.T) {
     chunkInfo := new(ChunkInfo)
-    chunkInfo.decRef()
-    noPanic = true
+    if _, err := chunkInfo.decRef(); err != ErrDoubleFree {
+        t.Errorf("should return ErrDoubleFree, got %v", err)
+    }
+    chunkInfo.refs = CHUNK_UNUSED
+    if _, err := chunkInfo.decRef(); err != ErrNotAllocated {
+        t.Errorf("should return ErrNotAllocated, got %v", err)
+    }
+    if chunkInfo.refs != CHUNK_UNUSED {
+        t.Errorf("refs should not be changed")
+    }
 }
@@ -0,0 +1,115 @@
+/* chunk_waiter.go - goroutines waiting for chunks of SlabClass */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, add waiterAbandon() and hasWaiters()
+*/
+/*
+DESCRIPTION
+    When a SlabClass is exhausted, GetContext() parks the caller in the
+    waiter list of the SlabClass. A chunk released by Put()/DecRef() is
+    handed to the first waiter directly instead of added to free list,
+    so waiters of each SlabClass are woken in FIFO order.
+
+    Memory released by shrinking slabs may be used by any SlabClass, so
+    all waiters are woken by the notifier of the pool to try again.
+*/
+package slab_pool
+
+import (
+    "container/list"
+    "errors"
+    "sync/atomic"
+)
+
+type chunkWaiter struct {
+    ch   chan chunkRef // receive chunk handed off (buffered)
+    elem *list.Element // element in waiter list (nil if not waiting)
+}
+
+func newChunkWaiter() *chunkWaiter {
+    w := new(chunkWaiter)
+    w.ch = make(chan chunkRef, 1)
+    return w
+}
+
+// allocate chunk, or add 'w' to waiter list if slab class is exhausted
+func (sc *SlabClass) chunkAllocOrWait(w *chunkWaiter) (*Slab, int, error) {
+    sc.lock()
+    defer sc.unlock()
+
+    // a chunk has been handed to 'w'
+    select {
+    case ref := <-w.ch:
+        return ref.slab, ref.index, nil
+    default:
+    }
+
+    slab, chunkIndex, err := sc.allocChunk()
+    if err == nil {
+        if w.elem != nil {
+            sc.waiterRemove(w)
+        }
+        return slab, chunkIndex, nil
+    }
+    if errors.Is(err, ErrPoolExhausted) && w.elem == nil {
+        w.elem = sc.waiters.PushBack(w)
+        atomic.AddInt32(&sc.waiting, 1)
+    }
+    return nil, -1, err
+}
+
+// remove 'w' from waiter list, return false if a chunk is handed to 'w'
+func (sc *SlabClass) waiterCancel(w *chunkWaiter) bool {
+    sc.lock()
+    defer sc.unlock()
+
+    if w.elem == nil {
+        return false
+    }
+    sc.waiterRemove(w)
+    return true
+}
+
+// remove 'w' from waiter list when it gives up waiting. A chunk already
+// handed to 'w' is handed to the next waiter, or added to free list
+func (sc *SlabClass) waiterAbandon(w *chunkWaiter) {
+    if sc.waiterCancel(w) {
+        return
+    }
+    select {
+    case ref := <-w.ch:
+        ref.slab.chunkInfo[ref.index].setRef(0)
+        sc.chunkReleaseBatch([]chunkRef{ref})
+    default:
+    }
+}
+
+// remove 'w' from waiter list (caller should hold the lock)
+func (sc *SlabClass) waiterRemove(w *chunkWaiter) {
+    sc.waiters.Remove(w.elem)
+    w.elem = nil
+    atomic.AddInt32(&sc.waiting, -1)
+}
+
+// check whether any goroutine is waiting for chunks (without lock)
+func (sc *SlabClass) hasWaiters() bool {
+    return atomic.LoadInt32(&sc.waiting) > 0
+}
+
+// hand chunk to the first waiter, return false if there is no waiter
+// (caller should hold the lock)
+func (sc *SlabClass) chunkHandOff(slab *Slab, chunkIndex int) bool {
+    e := sc.waiters.Front()
+    if e == nil {
+        return false
+    }
+    w := sc.waiters.Remove(e).(*chunkWaiter)
+    w.elem = nil
+    atomic.AddInt32(&sc.waiting, -1)
+
+    slab.chunkInfo[chunkIndex].setRef(1)
+    w.ch <- chunkRef{slab, chunkIndex}
+    return true
+}
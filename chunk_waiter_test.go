@@ -0,0 +1,201 @@
+/* chunk_waiter_test.go - unit test for chunk_waiter.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "context"
+    "errors"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+// wait until count of waiters of slab class equals 'count'
+func waitForWaiters(sc *SlabClass, count int) bool {
+    deadline := time.Now().Add(2 * time.Second)
+    for time.Now().Before(deadline) {
+        sc.lock()
+        n := sc.waiters.Len()
+        sc.unlock()
+        if n == count {
+            return true
+        }
+        time.Sleep(time.Millisecond)
+    }
+    return false
+}
+
+func TestWaiterFIFO(t *testing.T) {
+    options := &Options{MaxSlabsPerClass: 1, ExhaustPolicy: EXHAUST_BLOCK}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 4096, 4096, 2, options)
+    slabClass := slabPool.slabClasses[0]
+    chunk, _ := slabPool.Get(4096)
+
+    // two waiters in order
+    results := make(chan int, 2)
+    chunks := make(chan []byte, 2)
+    for i := 0; i < 2; i++ {
+        go func(id int) {
+            chunk, err := slabPool.GetContext(context.Background(), 4096)
+            if err != nil {
+                t.Errorf("unexpected error: %s", err)
+            }
+            results <- id
+            chunks <- chunk
+        }(i)
+        if !waitForWaiters(slabClass, i+1) {
+            t.Fatalf("waiter %d should be parked", i)
+        }
+    }
+
+    // wake up waiters one by one
+    slabPool.Put(chunk)
+    if id := <-results; id != 0 {
+        t.Errorf("waiter 0 should be woken first, got %d", id)
+    }
+    slabPool.Put(<-chunks)
+    if id := <-results; id != 1 {
+        t.Errorf("waiter 1 should be woken, got %d", id)
+    }
+    slab, chunkIndex, _ := slabPool.locate(<-chunks)
+    if slab.chunkInfo[chunkIndex].refs != 1 {
+        t.Errorf("chunk refs should be 1")
+    }
+}
+
+func TestWaiterCancel(t *testing.T) {
+    options := &Options{MaxSlabsPerClass: 1, ExhaustPolicy: EXHAUST_BLOCK}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 4096, 4096, 2, options)
+    slabClass := slabPool.slabClasses[0]
+    chunk, _ := slabPool.Get(4096)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan error)
+    go func() {
+        _, err := slabPool.GetContext(ctx, 4096)
+        done <- err
+    }()
+    if !waitForWaiters(slabClass, 1) {
+        t.Fatalf("waiter should be parked")
+    }
+    cancel()
+    if err := <-done; err != context.Canceled {
+        t.Errorf("should return context.Canceled, got %v", err)
+    }
+    if slabClass.waiters.Len() != 0 {
+        t.Errorf("waiter should be removed")
+    }
+
+    // chunk goes back to free list
+    slabPool.Put(chunk)
+    if !slabClass.listEmpty(SLAB_FULL) || slabClass.listEmpty(SLAB_FREE) {
+        t.Errorf("slab should be free")
+    }
+}
+
+func TestWaiterMemFreed(t *testing.T) {
+    options := &Options{MaxBytes: 4096 + int64(SLAB_FOOTER_LEN),
+                        ExhaustPolicy: EXHAUST_BLOCK}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 2048, 4096, 2, options)
+    chunk, _ := slabPool.Get(4096)
+
+    // waiter of another slab class
+    done := make(chan []byte)
+    go func() {
+        chunk, _ := slabPool.GetContext(context.Background(), 2048)
+        done <- chunk
+    }()
+    if !waitForWaiters(slabPool.slabClasses[0], 1) {
+        t.Fatalf("waiter should be parked")
+    }
+
+    // woken when slab becomes free, and memory of the slab is reclaimed
+    slabPool.Put(chunk)
+    if chunk := <-done; len(chunk) != 2048 {
+        t.Errorf("should return valid chunk")
+    }
+    if slabPool.slabClasses[0].waiters.Len() != 0 {
+        t.Errorf("waiter should be removed")
+    }
+}
+
+// provider failing on the 'failAt'-th call of Alloc()
+type failingProvider struct {
+    HeapProvider
+    calls  int32
+    failAt int32
+}
+
+func (p *failingProvider) Alloc(size int) ([]byte, error) {
+    if atomic.AddInt32(&p.calls, 1) == p.failAt {
+        return nil, errors.New("alloc failed")
+    }
+    return p.HeapProvider.Alloc(size)
+}
+
+func TestWaiterAllocError(t *testing.T) {
+    provider := &failingProvider{failAt: 2}
+    options := &Options{MaxBytes: 4096 + int64(SLAB_FOOTER_LEN),
+                        ExhaustPolicy: EXHAUST_BLOCK, MemoryProvider: provider}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 2048, 4096, 2, options)
+    slabClass := slabPool.slabClasses[0]
+    chunk := mustGet(t, slabPool, 4096)
+
+    // waiter fails to alloc slab after memory is reclaimed
+    done := make(chan error)
+    go func() {
+        _, err := slabPool.GetContext(context.Background(), 2048)
+        done <- err
+    }()
+    if !waitForWaiters(slabClass, 1) {
+        t.Fatalf("waiter should be parked")
+    }
+    slabPool.Put(chunk)
+    if err := <-done; err == nil || errors.Is(err, ErrPoolExhausted) {
+        t.Errorf("should return error of provider, got %v", err)
+    }
+    if slabClass.waiters.Len() != 0 {
+        t.Errorf("waiter should be removed")
+    }
+
+    // chunk released is not handed to the waiter gone
+    slabPool.Put(mustGet(t, slabPool, 2048))
+    if slabClass.listLen[SLAB_FREE] != 1 || slabPool.Shrink(0) != 1 {
+        t.Errorf("slab should be free")
+    }
+}
+
+func TestWaiterCachePut(t *testing.T) {
+    options := &Options{MaxSlabsPerClass: 1, ExhaustPolicy: EXHAUST_BLOCK}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 4096, 4096, 2, options)
+    slabClass := slabPool.slabClasses[0]
+    cache := slabPool.NewChunkCache(nil)
+    chunk, err := cache.Get(4096)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+    defer cancel()
+    done := make(chan error)
+    go func() {
+        _, err := slabPool.GetContext(ctx, 4096)
+        done <- err
+    }()
+    if !waitForWaiters(slabClass, 1) {
+        t.Fatalf("waiter should be parked")
+    }
+
+    // chunk put to cache is handed to the waiter
+    cache.Put(chunk)
+    if err := <-done; err != nil {
+        t.Errorf("waiter should get the chunk, got %v", err)
+    }
+}
@@ -0,0 +1,149 @@
+/* debug_handler.go - HTTP debug endpoint and expvar of SlabPool */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, add slab size of slab class
+2026/10/17, by agent, add large chunks
+*/
+/*
+DESCRIPTION
+    DebugHandler() renders the class table of pool, with slab list
+    occupancy and fragmentation of each slab class, for inspecting a live
+    process (in the spirit of net/http/pprof). The format is decided by
+    query "format":
+    - format=json: JSON
+    - format=text: plain text
+    - otherwise  : HTML page
+
+    PublishExpvar() publishes the same JSON in expvar (/debug/vars).
+
+Usage:
+    http.Handle("/debug/slabpool", slabPool.DebugHandler())
+    slabPool.PublishExpvar("slabpool")
+*/
+package slab_pool
+
+import (
+    "encoding/json"
+    "expvar"
+    "fmt"
+    "html/template"
+    "io"
+    "net/http"
+    "text/tabwriter"
+)
+
+// debug info of slab pool
+type debugInfo struct {
+    *Stats
+    Fragmentation float64
+    Classes       []debugClass
+}
+
+// debug info of slab class
+type debugClass struct {
+    ClassStats
+    Fragmentation float64
+}
+
+// collect debug info of slab pool
+func (sp *SlabPool) debugInfo() *debugInfo {
+    stats := sp.Stats()
+    info := &debugInfo{Stats: stats, Fragmentation: stats.Fragmentation()}
+    info.Classes = make([]debugClass, len(stats.Classes))
+    for i := range stats.Classes {
+        info.Classes[i].ClassStats = stats.Classes[i]
+        info.Classes[i].Fragmentation = stats.Classes[i].Fragmentation()
+    }
+    return info
+}
+
+/* DebugHandler - create http handler for debug page of slab pool
+ *
+ * Return:
+ *     - http handler
+ */
+func (sp *SlabPool) DebugHandler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        info := sp.debugInfo()
+        switch r.FormValue("format") {
+        case "json":
+            w.Header().Set("Content-Type", "application/json")
+            json.NewEncoder(w).Encode(info)
+        case "text":
+            w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+            writeDebugText(w, info)
+        default:
+            w.Header().Set("Content-Type", "text/html; charset=utf-8")
+            debugTemplate.Execute(w, info)
+        }
+    })
+}
+
+/* PublishExpvar - publish debug info of slab pool in expvar
+ *
+ * Params:
+ *     - name: name of expvar variable
+ *
+ * Note:
+ *     It panics if the name is already published (see expvar.Publish)
+ */
+func (sp *SlabPool) PublishExpvar(name string) {
+    expvar.Publish(name, expvar.Func(func() interface{} {
+        return sp.debugInfo()
+    }))
+}
+
+// write debug info as plain text
+func writeDebugText(w io.Writer, info *debugInfo) {
+    fmt.Fprintf(w, "slabs: %d, slab bytes: %d, chunks in use: %d, "+
+                "fragmentation: %.2f%%, heap allocs: %d\n",
+                info.Slabs, info.SlabBytes, info.ChunksInUse,
+                info.Fragmentation*100, info.HeapAllocs)
+    fmt.Fprintf(w, "large chunks: %d, large bytes: %d, large allocs: %d\n\n",
+                info.LargeChunks, info.LargeBytes, info.LargeAllocs)
+
+    tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintf(tw, "chunk size\tslab size\tslabs\tfree\tuse\tfull\tchunks in use\t"+
+                "requested\tin use\tfragmentation\tgets\tputs\t\n")
+    for _, c := range info.Classes {
+        fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%.2f%%\t%d\t%d\t\n",
+                    c.ChunkSize, c.SlabSize, c.Slabs, c.FreeSlabs, c.UseSlabs, c.FullSlabs,
+                    c.ChunksInUse, c.BytesRequested, c.BytesInUse,
+                    c.Fragmentation*100, c.Gets, c.Puts)
+    }
+    tw.Flush()
+}
+
+var debugTemplate = template.Must(template.New("slabpool").Funcs(template.FuncMap{
+    "percent": func(f float64) string { return fmt.Sprintf("%.2f%%", f*100) },
+}).Parse(`<html>
+<head>
+<title>slab pool</title>
+<style>
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 8px; text-align: right; }
+</style>
+</head>
+<body>
+<p>slabs: {{.Slabs}}, slab bytes: {{.SlabBytes}}, chunks in use: {{.ChunksInUse}},
+fragmentation: {{percent .Fragmentation}}, heap allocs: {{.HeapAllocs}}</p>
+<p>large chunks: {{.LargeChunks}}, large bytes: {{.LargeBytes}},
+large allocs: {{.LargeAllocs}}</p>
+<table>
+<tr><th>chunk size</th><th>slab size</th><th>slabs</th><th>free</th><th>use</th><th>full</th>
+<th>chunks in use</th><th>requested</th><th>in use</th><th>fragmentation</th>
+<th>gets</th><th>puts</th><th>increfs</th><th>decrefs</th>
+<th>slab allocs</th><th>slab releases</th></tr>
+{{range .Classes}}<tr><td>{{.ChunkSize}}</td><td>{{.SlabSize}}</td><td>{{.Slabs}}</td>
+<td>{{.FreeSlabs}}</td><td>{{.UseSlabs}}</td><td>{{.FullSlabs}}</td>
+<td>{{.ChunksInUse}}</td><td>{{.BytesRequested}}</td><td>{{.BytesInUse}}</td>
+<td>{{percent .Fragmentation}}</td><td>{{.Gets}}</td><td>{{.Puts}}</td>
+<td>{{.IncRefs}}</td><td>{{.DecRefs}}</td>
+<td>{{.SlabAllocs}}</td><td>{{.SlabReleases}}</td></tr>
+{{end}}</table>
+<p><a href="?format=json">json</a> <a href="?format=text">text</a></p>
+</body>
+</html>
+`))
@@ -0,0 +1,77 @@
+/* debug_handler_test.go - unit test for debug_handler.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, publish expvar with unique names
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "encoding/json"
+    "expvar"
+    "fmt"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 512, 1024, 2)
+    slabPool.Get(1000)
+    slabPool.Get(256)
+    handler := slabPool.DebugHandler()
+
+    // json
+    w := httptest.NewRecorder()
+    handler.ServeHTTP(w, httptest.NewRequest("GET", "/debug/slabpool?format=json", nil))
+    var info struct {
+        Slabs   int
+        Classes []struct {
+            ChunkSize     int
+            UseSlabs      int
+            Fragmentation float64
+        }
+    }
+    if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+        t.Fatalf("wrong json: %s", err)
+    }
+    if info.Slabs != 2 || len(info.Classes) != 2 || info.Classes[0].UseSlabs != 1 ||
+       info.Classes[0].Fragmentation != 0.5 {
+        t.Errorf("wrong json: %s", w.Body.String())
+    }
+
+    // text
+    w = httptest.NewRecorder()
+    handler.ServeHTTP(w, httptest.NewRequest("GET", "/debug/slabpool?format=text", nil))
+    if !strings.Contains(w.Body.String(), "fragmentation: 18.23%") {
+        t.Errorf("wrong text: %s", w.Body.String())
+    }
+
+    // html
+    w = httptest.NewRecorder()
+    handler.ServeHTTP(w, httptest.NewRequest("GET", "/debug/slabpool", nil))
+    if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") ||
+       !strings.Contains(w.Body.String(), "<td>1024</td>") {
+        t.Errorf("wrong html: %s", w.Body.String())
+    }
+}
+
+// count of expvar published, for unique names when tests are rerun
+var expvarCount int
+
+func TestPublishExpvar(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 512, 1024, 2)
+    slabPool.Get(1000)
+    expvarCount++
+    name := fmt.Sprintf("slabpool_test_%d", expvarCount)
+    slabPool.PublishExpvar(name)
+
+    v := expvar.Get(name)
+    if v == nil || !strings.Contains(v.String(), "\"ChunksInUse\":1") {
+        t.Errorf("debug info should be published")
+    }
+}
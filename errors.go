@@ -0,0 +1,36 @@
+/* errors.go - errors returned by SlabPool */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+    Errors could be checked by errors.Is(), since they may be wrapped
+    with more details.
+*/
+package slab_pool
+
+import (
+    "errors"
+)
+
+var (
+    // memory limit of pool is hit
+    ErrPoolExhausted = errors.New("slab pool exhausted")
+
+    // chunk is not allocated from this pool, or its slab has been released
+    ErrInvalidChunk = errors.New("invalid chunk")
+
+    // chunk is released more times than referenced
+    ErrDoubleFree = errors.New("chunk double free")
+
+    // chunk released has never been allocated
+    ErrNotAllocated = errors.New("chunk not allocated")
+
+    // reference is increased for chunk freed
+    ErrChunkFreed = errors.New("chunk already freed")
+
+    // chunk is corrupted (debug mode only), see CorruptionError
+    ErrChunkCorrupted = errors.New("chunk corrupted")
+)
@@ -0,0 +1,99 @@
+/* large_alloc.go - allocation of chunks larger than chunkSizeMax */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, separate budget in arena mode, follow exhaust policy
+2026/10/17, by agent, check size of memory from provider
+2026/10/17, by agent, reject sizes overflowing slab memory
+*/
+/*
+DESCRIPTION
+    With Options.LargeAlloc, Get() serves sizes above chunkSizeMax instead
+    of returning error. Each large chunk is the only chunk of a dedicated
+    slab without slab class, so Put()/IncRef()/DecRef() accept it as usual,
+    and its memory is freed as soon as it is released.
+
+    Memory of large slabs is from MemoryProvider (e.g. mmap), or Go heap in
+    arena mode since the arena is reserved for slab classes. It is counted
+    in MaxBytes (not in arena mode, where MaxBytes is the budget of arena),
+    and reported by Stats().LargeChunks/LargeBytes/LargeAllocs.
+
+    When MaxBytes is hit, free slabs are reclaimed for the large chunk. If
+    still no room, a chunk from Go heap is returned with EXHAUST_HEAP, and
+    ErrPoolExhausted is returned otherwise (GetContext() does not block for
+    large chunks).
+
+Usage:
+    slabPool, err := CreateSlabPoolWithOptions(4096, 128, 1024, 2,
+                                               &Options{LargeAlloc: true})
+    chunk, err := slabPool.Get(100000)
+    ...
+    err = slabPool.Put(chunk)
+*/
+package slab_pool
+
+import (
+    "fmt"
+    "math"
+    "sync/atomic"
+)
+
+// provider of memory for large slabs (nil for Go heap)
+func (sp *SlabPool) largeProvider() MemoryProvider {
+    if sp.arena == nil {
+        return sp.options.MemoryProvider
+    }
+    if sp.options.Mmap {
+        return sp.mmapProvider()
+    }
+    return nil
+}
+
+// allocate a large chunk with length 'size', by a dedicated slab
+func (sp *SlabPool) largeAlloc(size int) ([]byte, error) {
+    if size > math.MaxInt-SLAB_FOOTER_LEN {
+        return nil, fmt.Errorf("illegal chunk size: %d", size)
+    }
+    memSize := int64(size + SLAB_FOOTER_LEN)
+    if !sp.largeLimit.reserve(memSize) {
+        if !sp.reclaim(nil, memSize) || !sp.largeLimit.reserve(memSize) {
+            return nil, fmt.Errorf("Get(): %w", ErrPoolExhausted)
+        }
+    }
+
+    var slab *Slab
+    if provider := sp.largeProvider(); provider != nil {
+        memory, err := providerAlloc(provider, size+SLAB_FOOTER_LEN)
+        if err != nil {
+            sp.largeLimit.release(memSize)
+            return nil, fmt.Errorf("Get(): alloc slab memory: %w", err)
+        }
+        slab = newSlab(nil, memory, size, sp.slabMagic)
+    } else {
+        slab = NewSlab(nil, size, size, sp.slabMagic)
+    }
+    slab.large = true
+    sp.table.register(slab)
+
+    atomic.AddUint64(&sp.largeAllocs, 1)
+    atomic.AddInt64(&sp.largeChunks, 1)
+    atomic.AddInt64(&sp.largeBytes, memSize)
+    return slab.chunkAlloc(), nil
+}
+
+// release dedicated slab of large chunk (already removed from slabTable)
+func (sp *SlabPool) largeRelease(slab *Slab) {
+    memSize := int64(slab.slabSize + SLAB_FOOTER_LEN)
+    memory := slab.release()
+    if provider := sp.largeProvider(); provider != nil {
+        provider.Free(memory)
+    }
+    sp.largeLimit.release(memSize)
+
+    atomic.AddInt64(&sp.largeChunks, -1)
+    atomic.AddInt64(&sp.largeBytes, -memSize)
+    if sp.freed != nil {
+        sp.freed.notify()
+    }
+}
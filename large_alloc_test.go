@@ -0,0 +1,206 @@
+/* large_alloc_test.go - unit test for large_alloc.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "errors"
+    "math"
+    "testing"
+)
+
+func TestLargeAlloc(t *testing.T) {
+    // rejected without LargeAlloc
+    slabPool, _ := CreateSlabPool(4096, 128, 1024, 2)
+    if _, err := slabPool.Get(10000); err == nil {
+        t.Errorf("should fail to allocate chunk larger than chunkSizeMax")
+    }
+
+    slabPool, _ = CreateSlabPoolWithOptions(4096, 128, 1024, 2, &Options{LargeAlloc: true})
+    chunk, err := slabPool.Get(10000)
+    if err != nil || len(chunk) != 10000 {
+        t.Fatalf("should allocate large chunk: %v", err)
+    }
+    small := mustGet(t, slabPool, 100)
+
+    stats := slabPool.Stats()
+    if stats.LargeChunks != 1 || stats.LargeBytes != int64(10000+SLAB_FOOTER_LEN) ||
+       stats.LargeAllocs != 1 {
+        t.Errorf("wrong stats of large chunks: %+v", stats)
+    }
+    if stats.Slabs != 1 || stats.ChunksInUse != 1 || stats.Gets != 1 {
+        t.Errorf("large chunk should not be counted in slab classes: %+v", stats)
+    }
+
+    // reference operations
+    if err := slabPool.IncRef(chunk); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if err := slabPool.DecRef(chunk); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if slabPool.Stats().LargeChunks != 1 {
+        t.Errorf("large chunk should not be released while referenced")
+    }
+    if err := slabPool.Put(chunk); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    stats = slabPool.Stats()
+    if stats.LargeChunks != 0 || stats.LargeBytes != 0 || stats.LargeAllocs != 1 {
+        t.Errorf("large chunk should be released: %+v", stats)
+    }
+    if err := slabPool.Put(chunk); err == nil {
+        t.Errorf("expected error for large chunk released")
+    }
+    if err := slabPool.Put(small); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+
+    // size still illegal
+    if _, err := slabPool.Get(0); err == nil {
+        t.Errorf("should fail to allocate chunk of size 0")
+    }
+
+    // size overflowing slab memory
+    options := &Options{LargeAlloc: true, MaxBytes: 1 << 20, ExhaustPolicy: EXHAUST_HEAP}
+    slabPool, _ = CreateSlabPoolWithOptions(4096, 128, 1024, 2, options)
+    for _, size := range []int{math.MaxInt, math.MaxInt - SLAB_FOOTER_LEN + 1} {
+        if _, err := slabPool.Get(size); err == nil {
+            t.Errorf("should fail to allocate chunk of size %d", size)
+        }
+    }
+}
+
+func TestLargeAllocLimit(t *testing.T) {
+    options := &Options{LargeAlloc: true, MaxBytes: 16384}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2, options)
+    chunk := mustGet(t, slabPool, 10000)
+    if _, err := slabPool.Get(10000); !errors.Is(err, ErrPoolExhausted) {
+        t.Errorf("expected ErrPoolExhausted, got %v", err)
+    }
+    slabPool.Put(chunk)
+    chunk = mustGet(t, slabPool, 10000)
+    slabPool.Put(chunk)
+}
+
+func TestLargeAllocProvider(t *testing.T) {
+    provider := new(countingProvider)
+    options := &Options{LargeAlloc: true, MemoryProvider: provider}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2, options)
+
+    chunk := mustGet(t, slabPool, 10000)
+    if provider.allocs != 1 {
+        t.Errorf("Alloc() should be called once, got %d", provider.allocs)
+    }
+    slabPool.Put(chunk)
+    if provider.frees != 1 {
+        t.Errorf("Free() should be called once, got %d", provider.frees)
+    }
+}
+
+func TestLargeAllocCache(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2, &Options{LargeAlloc: true})
+    cache := slabPool.NewChunkCache(nil)
+    chunk, err := cache.Get(5000)
+    if err != nil || len(chunk) != 5000 {
+        t.Fatalf("should allocate large chunk from cache: %v", err)
+    }
+    if err := cache.Put(chunk); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if slabPool.Stats().LargeChunks != 0 {
+        t.Errorf("large chunk should be released")
+    }
+}
+
+func TestLargeAllocExhaust(t *testing.T) {
+    // free slab is reclaimed for large chunk
+    options := &Options{LargeAlloc: true, MaxBytes: 16384}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2, options)
+    slabPool.Put(mustGet(t, slabPool, 1024))
+    chunk := mustGet(t, slabPool, 12300)
+    if stats := slabPool.Stats(); stats.Slabs != 0 || stats.LargeChunks != 1 {
+        t.Errorf("free slab should be reclaimed: %+v", stats)
+    }
+    slabPool.Put(chunk)
+
+    // chunk from Go heap with EXHAUST_HEAP
+    options = &Options{LargeAlloc: true, MaxBytes: 8192, ExhaustPolicy: EXHAUST_HEAP}
+    slabPool, _ = CreateSlabPoolWithOptions(4096, 128, 1024, 2, options)
+    chunk, err := slabPool.Get(10000)
+    if err != nil || len(chunk) != 10000 {
+        t.Fatalf("should return chunk from heap: %v", err)
+    }
+    if stats := slabPool.Stats(); stats.HeapAllocs != 1 || stats.LargeChunks != 0 {
+        t.Errorf("chunk should be from heap: %+v", stats)
+    }
+    if err := slabPool.Put(chunk); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+
+    // not blocked with EXHAUST_BLOCK
+    options = &Options{LargeAlloc: true, MaxBytes: 8192, ExhaustPolicy: EXHAUST_BLOCK}
+    slabPool, _ = CreateSlabPoolWithOptions(4096, 128, 1024, 2, options)
+    if _, err := slabPool.Get(10000); !errors.Is(err, ErrPoolExhausted) {
+        t.Errorf("expected ErrPoolExhausted, got %v", err)
+    }
+}
+
+func TestLargeAllocArena(t *testing.T) {
+    // large chunk is not counted in budget of arena
+    options := &Options{LargeAlloc: true, ArenaSize: 2 * (4096 + SLAB_FOOTER_LEN)}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2, options)
+    large := mustGet(t, slabPool, 5000)
+    chunk1 := mustGet(t, slabPool, 1024)
+    chunk2 := mustGet(t, slabPool, 128)
+    if slabPool.arena.contains(large) || !slabPool.arena.contains(chunk1) ||
+       !slabPool.arena.contains(chunk2) {
+        t.Errorf("only chunks of slab classes should be in arena")
+    }
+    for _, chunk := range [][]byte{large, chunk1, chunk2} {
+        if err := slabPool.Put(chunk); err != nil {
+            t.Errorf("unexpected error: %s", err)
+        }
+    }
+}
+
+func TestLargeAllocLeakReport(t *testing.T) {
+    options := &Options{LargeAlloc: true, Concurrent: true, TrackAllocations: true}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2, options)
+
+    // LeakReport() alongside release of large chunks
+    stop := make(chan struct{})
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        for {
+            select {
+            case <-stop:
+                return
+            default:
+                slabPool.LeakReport()
+            }
+        }
+    }()
+    chunks := make([][]byte, 0)
+    for i := 0; i < 10000; i++ {
+        chunks = append(chunks, mustGet(t, slabPool, 5000))
+        if len(chunks) == 10 {
+            for _, chunk := range chunks {
+                slabPool.Put(chunk)
+            }
+            chunks = chunks[:0]
+        }
+    }
+    close(stop)
+    <-done
+    if report := slabPool.LeakReport(); len(report) != 0 {
+        t.Errorf("no chunk should be leaked: %v", report)
+    }
+}
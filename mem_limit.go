@@ -0,0 +1,89 @@
+/* mem_limit.go - memory limit of slab pool */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, add fits()
+*/
+/*
+DESCRIPTION
+    memLimit tracks memory of slabs against a byte budget shared by all
+    SlabClasses of a pool. notifier wakes up goroutines waiting for
+    memory when chunks or slabs are released.
+*/
+package slab_pool
+
+import (
+    "sync"
+    "sync/atomic"
+)
+
+type memLimit struct {
+    maxBytes  int64 // max bytes of slab memory (0 for unlimited)
+    usedBytes int64 // bytes of slab memory in use (accessed atomically)
+}
+
+// reserve memory of 'size' bytes, return false if exceeds the limit
+func (l *memLimit) reserve(size int64) bool {
+    for {
+        used := atomic.LoadInt64(&l.usedBytes)
+        if l.maxBytes > 0 && used+size > l.maxBytes {
+            return false
+        }
+        if atomic.CompareAndSwapInt64(&l.usedBytes, used, used+size) {
+            return true
+        }
+    }
+}
+
+// check whether memory of 'size' bytes could be reserved now
+func (l *memLimit) fits(size int64) bool {
+    return l.maxBytes <= 0 || atomic.LoadInt64(&l.usedBytes)+size <= l.maxBytes
+}
+
+// release memory of 'size' bytes
+func (l *memLimit) release(size int64) {
+    atomic.AddInt64(&l.usedBytes, -size)
+}
+
+// return bytes of slab memory in use
+func (l *memLimit) used() int64 {
+    return atomic.LoadInt64(&l.usedBytes)
+}
+
+type notifier struct {
+    waiters int32         // count of waiters (accessed atomically)
+    mutex   sync.Mutex    // protect ch
+    ch      chan struct{} // closed to wake up all waiters
+}
+
+func newNotifier() *notifier {
+    n := new(notifier)
+    n.ch = make(chan struct{})
+    return n
+}
+
+// register a waiter, return channel to wait on. The waiter should try
+// again after register, and call leave() when done
+func (n *notifier) enter() <-chan struct{} {
+    atomic.AddInt32(&n.waiters, 1)
+    n.mutex.Lock()
+    defer n.mutex.Unlock()
+    return n.ch
+}
+
+// unregister a waiter
+func (n *notifier) leave() {
+    atomic.AddInt32(&n.waiters, -1)
+}
+
+// wake up all waiters
+func (n *notifier) notify() {
+    if atomic.LoadInt32(&n.waiters) == 0 {
+        return
+    }
+    n.mutex.Lock()
+    defer n.mutex.Unlock()
+    close(n.ch)
+    n.ch = make(chan struct{})
+}
@@ -0,0 +1,155 @@
+/* mem_limit_test.go - unit test for mem_limit.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestMemLimit(t *testing.T) {
+    limit := &memLimit{maxBytes: 100}
+    if !limit.reserve(60) || limit.used() != 60 {
+        t.Errorf("should reserve 60 bytes")
+    }
+    if limit.reserve(50) {
+        t.Errorf("should not reserve more than 100 bytes")
+    }
+    limit.release(60)
+    if !limit.reserve(100) || limit.used() != 100 {
+        t.Errorf("should reserve 100 bytes")
+    }
+
+    // unlimited
+    limit = &memLimit{}
+    if !limit.reserve(1 << 40) {
+        t.Errorf("should reserve memory without limit")
+    }
+}
+
+func TestExhaustError(t *testing.T) {
+    options := &Options{MaxBytes: 2 * (4096 + int64(SLAB_FOOTER_LEN))}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 1024, 2048, 2, options)
+
+    // two slabs at most
+    chunk1, _ := slabPool.Get(2048)
+    chunk2, _ := slabPool.Get(2048)
+    chunk3, _ := slabPool.Get(1024)
+    if chunk1 == nil || chunk2 == nil || chunk3 == nil {
+        t.Errorf("should return valid chunk")
+    }
+    _, err := slabPool.Get(2048)
+    if !errors.Is(err, ErrPoolExhausted) {
+        t.Errorf("should return ErrPoolExhausted, got %v", err)
+    }
+
+    // allocate after memory is freed
+    slabPool.Put(chunk1)
+    slabPool.Put(chunk2)
+    slabPool.Shrink(0)
+    if _, err := slabPool.Get(2048); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+}
+
+func TestMaxSlabsPerClass(t *testing.T) {
+    options := &Options{MaxSlabsPerClass: 1}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 1024, 2048, 2, options)
+
+    slabPool.Get(2048)
+    slabPool.Get(2048)
+    if _, err := slabPool.Get(2048); !errors.Is(err, ErrPoolExhausted) {
+        t.Errorf("should return ErrPoolExhausted, got %v", err)
+    }
+    if _, err := slabPool.Get(1024); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+}
+
+func TestExhaustHeap(t *testing.T) {
+    options := &Options{MaxSlabsPerClass: 1, ExhaustPolicy: EXHAUST_HEAP}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 4096, 4096, 2, options)
+
+    slabPool.Get(4096)
+    chunk, err := slabPool.Get(100)
+    if err != nil || len(chunk) != 100 {
+        t.Errorf("should return chunk from heap")
+    }
+    slab, _, err := slabPool.locate(chunk)
+    if err != nil || slab.slabClass != nil {
+        t.Errorf("chunk should be from heap")
+    }
+
+    // reference operations on heap chunk
+    if slabPool.IncRef(chunk) != nil || slabPool.DecRef(chunk) != nil ||
+       slabPool.Put(chunk) != nil {
+        t.Errorf("unexpected error for heap chunk")
+    }
+    if slabPool.table.lookup(chunk) != nil {
+        t.Errorf("slab of heap chunk should be removed")
+    }
+}
+
+func TestExhaustBlock(t *testing.T) {
+    options := &Options{MaxSlabsPerClass: 1, ExhaustPolicy: EXHAUST_BLOCK}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 4096, 4096, 2, options)
+    chunk, _ := slabPool.Get(4096)
+
+    // context is done before memory freed
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel()
+    if _, err := slabPool.GetContext(ctx, 4096); err != context.DeadlineExceeded {
+        t.Errorf("should return context.DeadlineExceeded, got %v", err)
+    }
+
+    // wake up when memory is freed
+    go func() {
+        time.Sleep(10 * time.Millisecond)
+        slabPool.Put(chunk)
+    }()
+    chunk2, err := slabPool.Get(4096)
+    if err != nil || len(chunk2) != 4096 {
+        t.Errorf("should return valid chunk")
+    }
+}
+
+func TestReclaimFreeSlabs(t *testing.T) {
+    // memory of a single slab, held by free slab of another class
+    options := &Options{MaxBytes: 1024 + int64(SLAB_FOOTER_LEN)}
+    slabPool, _ := CreateSlabPoolWithOptions(1024, 128, 1024, 2, options)
+    slabPool.Put(mustGet(t, slabPool, 1024))
+    chunk, err := slabPool.Get(100)
+    if err != nil || len(chunk) != 100 {
+        t.Fatalf("free slab should be reclaimed: %v", err)
+    }
+    stats := slabPool.Stats()
+    if stats.Slabs != 1 || stats.Classes[3].Slabs != 0 {
+        t.Errorf("free slab of class 1024 should be released: %+v", stats)
+    }
+
+    // no free slab to reclaim
+    if _, err := slabPool.Get(1024); !errors.Is(err, ErrPoolExhausted) {
+        t.Errorf("should return ErrPoolExhausted, got %v", err)
+    }
+    slabPool.Put(chunk)
+
+    // blocking policy
+    options.ExhaustPolicy = EXHAUST_BLOCK
+    slabPool, _ = CreateSlabPoolWithOptions(1024, 128, 1024, 2, options)
+    slabPool.Put(mustGet(t, slabPool, 1024))
+    ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+    defer cancel()
+    chunk, err = slabPool.GetContext(ctx, 100)
+    if err != nil || len(chunk) != 100 {
+        t.Errorf("free slab should be reclaimed: %v", err)
+    }
+}
@@ -0,0 +1,193 @@
+/* memory_provider.go - providers of slab memory */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, define HUGE_PAGE_SIZE for all platforms
+2026/10/17, by agent, cut off memory beyond size requested from provider
+2026/10/17, by agent, release arena when no memory block is in use
+*/
+/*
+DESCRIPTION
+    MemoryProvider is used by SlabPool to obtain and release the raw memory
+    of each slab. Built-in providers:
+        - HeapProvider : memory from Go heap
+        - MmapProvider : anonymous memory from mmap, invisible to GC
+        - ArenaProvider: memory carved from a preallocated arena
+
+Usage:
+    arena := NewArenaProvider(64 << 20)
+    slabPool, err := CreateSlabPoolWithOptions(4096, 128, 1024, 2,
+                                               &Options{MemoryProvider: arena})
+*/
+package slab_pool
+
+import (
+    "fmt"
+    "sync"
+    "unsafe"
+)
+
+const (
+    ARENA_PAGE_SIZE = 4096    // page size for touching arena
+    HUGE_PAGE_SIZE  = 2 << 20 // size of transparent huge page
+)
+
+type MemoryProvider interface {
+    // Alloc returns memory of 'size' bytes for a slab. The memory should
+    // have cap equal to 'size' (capacity beyond 'size' is cut off by the
+    // pool). ErrPoolExhausted (or an error wrapping it) should be returned
+    // if provider has no memory any more.
+    Alloc(size int) ([]byte, error)
+
+    // Free releases memory returned by Alloc()
+    Free(memory []byte)
+}
+
+// alloc memory of 'size' bytes from provider. Since slab is located by
+// the end of chunk capacity, memory is resliced to cap 'size'
+func providerAlloc(provider MemoryProvider, size int) ([]byte, error) {
+    memory, err := provider.Alloc(size)
+    if err != nil {
+        return nil, err
+    }
+    if len(memory) < size {
+        provider.Free(memory)
+        return nil, fmt.Errorf("provider returns %d bytes, %d bytes expected",
+                               len(memory), size)
+    }
+    return memory[:size:size], nil
+}
+
+// HeapProvider provides memory from Go heap
+type HeapProvider struct{}
+
+func (HeapProvider) Alloc(size int) ([]byte, error) {
+    return make([]byte, size), nil
+}
+
+func (HeapProvider) Free(memory []byte) {
+}
+
+// MmapProvider provides anonymous memory from mmap
+type MmapProvider struct {
+    // HugePages aligns slab memory to HUGE_PAGE_SIZE and advises kernel
+    // to use transparent huge pages (MADV_HUGEPAGE) for it (Linux only)
+    HugePages bool
+
+    // Populate populates pages of slab memory up front
+    // (MAP_POPULATE/MADV_POPULATE_WRITE on Linux)
+    Populate bool
+}
+
+func (p MmapProvider) Alloc(size int) ([]byte, error) {
+    return mmapSlab(size, p.HugePages, p.Populate)
+}
+
+func (MmapProvider) Free(memory []byte) {
+    if err := munmapSlab(memory); err != nil {
+        panic(fmt.Sprintf("Free(): munmap slab memory: %s", err))
+    }
+}
+
+// ArenaProvider provides memory carved from a preallocated arena. Memory
+// freed is kept for later allocations with the same size.
+type ArenaProvider struct {
+    mutex  sync.Mutex
+    arena  []byte           // preallocated memory
+    offset int              // offset of unused memory in arena
+    free   map[int][][]byte // memory freed (indexed by size)
+    inUse  int              // count of memory blocks allocated
+}
+
+/* NewArenaProvider - create arena provider
+ *
+ * Params:
+ *     - capacity: size of arena (bytes)
+ *
+ * Return:
+ *     - arena provider
+ */
+func NewArenaProvider(capacity int) *ArenaProvider {
+    return newArenaProvider(make([]byte, capacity))
+}
+
+// create arena provider with preallocated memory
+func newArenaProvider(arena []byte) *ArenaProvider {
+    a := new(ArenaProvider)
+    a.arena = arena
+    a.free = make(map[int][][]byte)
+    return a
+}
+
+func (a *ArenaProvider) Alloc(size int) ([]byte, error) {
+    a.mutex.Lock()
+    defer a.mutex.Unlock()
+
+    // reuse memory freed
+    if free := a.free[size]; len(free) > 0 {
+        memory := free[len(free)-1]
+        a.free[size] = free[:len(free)-1]
+        a.inUse++
+        return memory, nil
+    }
+
+    // carve memory from arena
+    if a.offset+size > len(a.arena) {
+        return nil, fmt.Errorf("arena is full: %w", ErrPoolExhausted)
+    }
+    memory := a.arena[a.offset : a.offset+size : a.offset+size]
+    a.offset += size
+    a.inUse++
+    return memory, nil
+}
+
+func (a *ArenaProvider) Free(memory []byte) {
+    a.mutex.Lock()
+    defer a.mutex.Unlock()
+
+    a.free[len(memory)] = append(a.free[len(memory)], memory)
+    a.inUse--
+}
+
+// drop the whole arena if no memory block is in use, and return it (nil if
+// some block is still in use). Alloc() fails after that
+func (a *ArenaProvider) release() []byte {
+    a.mutex.Lock()
+    defer a.mutex.Unlock()
+
+    if a.inUse > 0 || a.arena == nil {
+        return nil
+    }
+    arena := a.arena
+    a.arena = nil
+    a.offset = 0
+    a.free = make(map[int][][]byte)
+    return arena
+}
+
+// carve the rest of arena into memory blocks of 'size' bytes, and touch
+// their pages
+func (a *ArenaProvider) carve(size int) {
+    a.mutex.Lock()
+    defer a.mutex.Unlock()
+
+    for a.offset+size <= len(a.arena) {
+        memory := a.arena[a.offset : a.offset+size : a.offset+size]
+        for i := 0; i < size; i += ARENA_PAGE_SIZE {
+            memory[i] = 0
+        }
+        a.free[size] = append(a.free[size], memory)
+        a.offset += size
+    }
+}
+
+// check whether 'chunk' is in arena
+func (a *ArenaProvider) contains(chunk []byte) bool {
+    if len(a.arena) == 0 || cap(chunk) == 0 {
+        return false
+    }
+    start := uintptr(unsafe.Pointer(&a.arena[0]))
+    addr := uintptr(unsafe.Pointer(&chunk[:cap(chunk)][0]))
+    return addr >= start && addr-start < uintptr(len(a.arena))
+}
@@ -0,0 +1,251 @@
+/* memory_provider_test.go - unit test for memory_provider.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "errors"
+    "strings"
+    "testing"
+)
+
+// provider counting calls of Alloc() and Free()
+type countingProvider struct {
+    HeapProvider
+    allocs int
+    frees  int
+}
+
+func (p *countingProvider) Alloc(size int) ([]byte, error) {
+    p.allocs++
+    return p.HeapProvider.Alloc(size)
+}
+
+func (p *countingProvider) Free(memory []byte) {
+    p.frees++
+}
+
+func TestCustomProvider(t *testing.T) {
+    provider := new(countingProvider)
+    options := &Options{MemoryProvider: provider}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 4096, 4096, 2, options)
+
+    chunk1, _ := slabPool.Get(4096)
+    chunk2, _ := slabPool.Get(4096)
+    if provider.allocs != 2 {
+        t.Errorf("Alloc() should be called twice, got %d", provider.allocs)
+    }
+
+    slabPool.Put(chunk1)
+    slabPool.Put(chunk2)
+    slabPool.Shrink(0)
+    if provider.frees != 2 {
+        t.Errorf("Free() should be called twice, got %d", provider.frees)
+    }
+}
+
+// provider returning memory with wrong size
+type sloppyProvider struct {
+    HeapProvider
+    extra int // bytes beyond (or below if negative) size requested
+}
+
+func (p *sloppyProvider) Alloc(size int) ([]byte, error) {
+    if p.extra < 0 {
+        return make([]byte, size+p.extra), nil
+    }
+    return make([]byte, size, size+p.extra), nil
+}
+
+func TestSloppyProvider(t *testing.T) {
+    // capacity beyond size is cut off
+    options := &Options{MemoryProvider: &sloppyProvider{extra: 64}}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2, options)
+    chunk := mustGet(t, slabPool, 100)
+    if err := slabPool.Put(chunk); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+
+    // memory shorter than size is rejected
+    options = &Options{MemoryProvider: &sloppyProvider{extra: -64}}
+    slabPool, _ = CreateSlabPoolWithOptions(4096, 128, 1024, 2, options)
+    if _, err := slabPool.Get(100); err == nil {
+        t.Errorf("should fail with memory shorter than slab")
+    }
+}
+
+func TestArenaProvider(t *testing.T) {
+    arena := NewArenaProvider(1000)
+
+    memory1, err := arena.Alloc(400)
+    if err != nil || len(memory1) != 400 || cap(memory1) != 400 {
+        t.Errorf("should return memory with size 400")
+    }
+    memory2, _ := arena.Alloc(400)
+    if _, err := arena.Alloc(400); !errors.Is(err, ErrPoolExhausted) {
+        t.Errorf("should return ErrPoolExhausted, got %v", err)
+    }
+
+    // reuse memory freed
+    arena.Free(memory2)
+    memory3, err := arena.Alloc(400)
+    if err != nil || &memory3[0] != &memory2[0] {
+        t.Errorf("should reuse memory freed")
+    }
+    memory4, err := arena.Alloc(200)
+    if err != nil || len(memory4) != 200 {
+        t.Errorf("should return memory with size 200")
+    }
+
+    // arena is released only if no memory is in use
+    arena.Free(memory1)
+    arena.Free(memory3)
+    if arena.release() != nil {
+        t.Errorf("arena should not be released while memory in use")
+    }
+    arena.Free(memory4)
+    if memory := arena.release(); len(memory) != 1000 {
+        t.Errorf("arena should be released")
+    }
+    if _, err := arena.Alloc(200); !errors.Is(err, ErrPoolExhausted) {
+        t.Errorf("should return ErrPoolExhausted after released, got %v", err)
+    }
+}
+
+func TestArenaProviderExhausted(t *testing.T) {
+    arena := NewArenaProvider(2 * (4096 + SLAB_FOOTER_LEN))
+    options := &Options{MemoryProvider: arena}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 4096, 4096, 2, options)
+
+    slabPool.Get(4096)
+    slabPool.Get(4096)
+    if _, err := slabPool.Get(4096); !errors.Is(err, ErrPoolExhausted) {
+        t.Errorf("should return ErrPoolExhausted, got %v", err)
+    }
+}
+
+func TestArenaMode(t *testing.T) {
+    arenaSize := 4 * (4096 + SLAB_FOOTER_LEN)
+    test := func(options *Options) {
+        slabPool, err := CreateSlabPoolWithOptions(4096, 1024, 4096, 2, options)
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if slabPool.limit.maxBytes != int64(arenaSize) {
+            t.Errorf("MaxBytes should be limited to ArenaSize")
+        }
+
+        // allocate all slabs from arena
+        chunks := make([][]byte, 0)
+        for i := 0; i < 4; i++ {
+            chunk, err := slabPool.Get(4096)
+            if err != nil || !slabPool.arena.contains(chunk) {
+                t.Errorf("should return chunk in arena")
+            }
+            chunks = append(chunks, chunk)
+        }
+        if _, err := slabPool.Get(1024); !errors.Is(err, ErrPoolExhausted) {
+            t.Errorf("should return ErrPoolExhausted, got %v", err)
+        }
+
+        // reuse slab memory of arena
+        for _, chunk := range chunks {
+            slabPool.Put(chunk)
+        }
+        slabPool.Shrink(0)
+        chunk, err := slabPool.Get(1024)
+        if err != nil || !slabPool.arena.contains(chunk) {
+            t.Errorf("should return chunk in arena")
+        }
+
+        // chunk not in arena
+        memory := make([]byte, 4096+SLAB_FOOTER_LEN)
+        copy(memory[4096:], chunk[:cap(chunk)][cap(chunk)-SLAB_FOOTER_LEN:])
+        err = slabPool.Put(memory[:1024])
+        if !errors.Is(err, ErrInvalidChunk) || !strings.Contains(err.Error(), "arena") {
+            t.Errorf("should return error for chunk not in arena, got %v", err)
+        }
+    }
+
+    test(&Options{ArenaSize: arenaSize})
+    test(&Options{ArenaSize: arenaSize, ArenaEager: true})
+}
+
+func TestArenaModeHeapChunk(t *testing.T) {
+    options := &Options{ArenaSize: 4096 + SLAB_FOOTER_LEN, ExhaustPolicy: EXHAUST_HEAP}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 4096, 4096, 2, options)
+    mustGet(t, slabPool, 4096)
+
+    // chunk from Go heap is not in arena, but still accepted
+    chunk := mustGet(t, slabPool, 4096)
+    if slabPool.arena.contains(chunk) {
+        t.Errorf("chunk should be from heap")
+    }
+    if err := slabPool.Put(chunk); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+}
+
+func TestArenaModeOptions(t *testing.T) {
+    _, err := CreateSlabPoolWithOptions(4096, 1024, 4096, 2, &Options{ArenaSize: 4096})
+    if err == nil {
+        t.Errorf("should return error due to small ArenaSize")
+    }
+    options := &Options{ArenaSize: 1 << 20, MemoryProvider: HeapProvider{}}
+    if _, err := CreateSlabPoolWithOptions(4096, 1024, 4096, 2, options); err == nil {
+        t.Errorf("should return error due to MemoryProvider")
+    }
+
+    // slab memory released by one slab class is reused by others
+    classes := []ClassConfig{{ChunkSize: 64, SlabSize: 1024}, {ChunkSize: 1024}}
+    options = &Options{ArenaSize: 1 << 20}
+    if _, err := CreateSlabPoolWithClasses(4096, classes, options); err == nil {
+        t.Errorf("should return error due to different slab sizes")
+    }
+}
+
+func TestHugePagesOptions(t *testing.T) {
+    wrongOptions := []*Options{
+        {HugePages: true},
+        {Populate: true},
+        {HugePages: true, Mmap: true, MemoryProvider: HeapProvider{}},
+        {HugePages: true, Mmap: true, ArenaSize: 1 << 20},
+    }
+    for _, options := range wrongOptions {
+        if _, err := CreateSlabPoolWithOptions(4096, 1024, 4096, 2, options); err == nil {
+            t.Errorf("wrong options should be rejected: %+v", options)
+        }
+    }
+
+    // slab memory rounded up to huge pages
+    options := &Options{HugePages: true, Mmap: true}
+    slabPool, err := CreateSlabPoolWithOptions(4096, 1024, 4096, 2, options)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    for _, slabClass := range slabPool.slabClasses {
+        if slabClass.slabSize != HUGE_PAGE_SIZE-SLAB_FOOTER_LEN {
+            t.Errorf("slab memory should be a huge page, got %d", slabClass.slabSize)
+        }
+    }
+}
+
+func TestArenaCarve(t *testing.T) {
+    arena := NewArenaProvider(10000)
+    arena.carve(3000)
+    if len(arena.free[3000]) != 3 || arena.offset != 9000 {
+        t.Errorf("arena should be carved into 3 blocks")
+    }
+    if _, err := arena.Alloc(1000); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if _, err := arena.Alloc(1000); err == nil {
+        t.Errorf("should return error when arena is full")
+    }
+}
@@ -0,0 +1,200 @@
+/* metrics.go - Prometheus metrics of SlabPool */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, add slab size of slab class
+2026/10/17, by agent, add metrics of large chunks
+*/
+/*
+DESCRIPTION
+    MetricsCollector exposes Stats() of slab pools in the Prometheus text
+    exposition format (version 0.0.4), without depending on any client
+    library. Each pool is registered with a name, which is the value of
+    label "pool", so several pools in one process can be told apart.
+    Metrics of slab classes are labelled by "chunk_size" too.
+
+Usage:
+    collector := NewMetricsCollector()
+    collector.Register("packet", slabPool)
+    http.Handle("/metrics", collector)
+*/
+package slab_pool
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "net/http"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+)
+
+// metric of slab class
+type classMetric struct {
+    name  string
+    help  string
+    typ   string // "gauge" or "counter"
+    value func(c *ClassStats) float64
+}
+
+// metric of slab pool
+type poolMetric struct {
+    name  string
+    help  string
+    typ   string // "gauge" or "counter"
+    value func(s *Stats) float64
+}
+
+var classMetrics = []classMetric{
+    {"slab_pool_class_slab_size_bytes", "Slab size of slab class.", "gauge",
+        func(c *ClassStats) float64 { return float64(c.SlabSize) }},
+    {"slab_pool_class_slabs", "Count of slabs in slab class.", "gauge",
+        func(c *ClassStats) float64 { return float64(c.Slabs) }},
+    {"slab_pool_class_free_slabs", "Count of slabs with no chunk allocated.", "gauge",
+        func(c *ClassStats) float64 { return float64(c.FreeSlabs) }},
+    {"slab_pool_class_use_slabs", "Count of slabs with some chunks allocated.", "gauge",
+        func(c *ClassStats) float64 { return float64(c.UseSlabs) }},
+    {"slab_pool_class_full_slabs", "Count of slabs with all chunks allocated.", "gauge",
+        func(c *ClassStats) float64 { return float64(c.FullSlabs) }},
+    {"slab_pool_class_chunks_in_use", "Count of chunks allocated and not released.", "gauge",
+        func(c *ClassStats) float64 { return float64(c.ChunksInUse) }},
+    {"slab_pool_class_requested_bytes", "Bytes requested for chunks in use.", "gauge",
+        func(c *ClassStats) float64 { return float64(c.BytesRequested) }},
+    {"slab_pool_class_in_use_bytes", "Bytes handed out for chunks in use.", "gauge",
+        func(c *ClassStats) float64 { return float64(c.BytesInUse) }},
+    {"slab_pool_class_gets_total", "Chunks allocated.", "counter",
+        func(c *ClassStats) float64 { return float64(c.Gets) }},
+    {"slab_pool_class_puts_total", "Calls of Put().", "counter",
+        func(c *ClassStats) float64 { return float64(c.Puts) }},
+    {"slab_pool_class_increfs_total", "Calls of IncRef().", "counter",
+        func(c *ClassStats) float64 { return float64(c.IncRefs) }},
+    {"slab_pool_class_decrefs_total", "Calls of DecRef().", "counter",
+        func(c *ClassStats) float64 { return float64(c.DecRefs) }},
+    {"slab_pool_class_slab_allocs_total", "Slabs allocated.", "counter",
+        func(c *ClassStats) float64 { return float64(c.SlabAllocs) }},
+    {"slab_pool_class_slab_releases_total", "Slabs released.", "counter",
+        func(c *ClassStats) float64 { return float64(c.SlabReleases) }},
+}
+
+var poolMetrics = []poolMetric{
+    {"slab_pool_slab_bytes", "Bytes of slab memory, including footers.", "gauge",
+        func(s *Stats) float64 { return float64(s.SlabBytes) }},
+    {"slab_pool_heap_allocs_total", "Chunks allocated from Go heap.", "counter",
+        func(s *Stats) float64 { return float64(s.HeapAllocs) }},
+    {"slab_pool_large_chunks", "Count of large chunks allocated and not released.", "gauge",
+        func(s *Stats) float64 { return float64(s.LargeChunks) }},
+    {"slab_pool_large_bytes", "Bytes of memory of large chunks, including footers.", "gauge",
+        func(s *Stats) float64 { return float64(s.LargeBytes) }},
+    {"slab_pool_large_allocs_total", "Large chunks allocated.", "counter",
+        func(s *Stats) float64 { return float64(s.LargeAllocs) }},
+}
+
+type MetricsCollector struct {
+    mutex sync.Mutex
+    pools map[string]*SlabPool // name => slab pool
+}
+
+/* NewMetricsCollector - create metrics collector
+ *
+ * Return:
+ *     - metrics collector with no pool
+ */
+func NewMetricsCollector() *MetricsCollector {
+    c := new(MetricsCollector)
+    c.pools = make(map[string]*SlabPool)
+    return c
+}
+
+/* Register - add slab pool to collector
+ *
+ * Params:
+ *     - name: value of label "pool" (replace pool registered with same name)
+ *     - sp  : slab pool
+ */
+func (c *MetricsCollector) Register(name string, sp *SlabPool) {
+    c.mutex.Lock()
+    defer c.mutex.Unlock()
+    c.pools[name] = sp
+}
+
+/* Unregister - remove slab pool from collector
+ *
+ * Params:
+ *     - name: name of slab pool
+ */
+func (c *MetricsCollector) Unregister(name string) {
+    c.mutex.Lock()
+    defer c.mutex.Unlock()
+    delete(c.pools, name)
+}
+
+/* WriteMetrics - write metrics in Prometheus text format
+ *
+ * Params:
+ *     - w: writer for metrics
+ *
+ * Return:
+ *     - err: error of writing
+ */
+func (c *MetricsCollector) WriteMetrics(w io.Writer) error {
+    // collect stats of pools (in order of name)
+    c.mutex.Lock()
+    names := make([]string, 0, len(c.pools))
+    for name := range c.pools {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    stats := make([]*Stats, len(names))
+    for i, name := range names {
+        stats[i] = c.pools[name].Stats()
+    }
+    c.mutex.Unlock()
+
+    // samples of a metric are written together
+    bw := bufio.NewWriter(w)
+    for _, m := range poolMetrics {
+        writeHeader(bw, m.name, m.help, m.typ)
+        for i, name := range names {
+            fmt.Fprintf(bw, "%s{pool=\"%s\"} %s\n", m.name, escapeLabel(name),
+                        formatValue(m.value(stats[i])))
+        }
+    }
+    for _, m := range classMetrics {
+        writeHeader(bw, m.name, m.help, m.typ)
+        for i, name := range names {
+            for j := range stats[i].Classes {
+                class := &stats[i].Classes[j]
+                fmt.Fprintf(bw, "%s{pool=\"%s\",chunk_size=\"%d\"} %s\n", m.name,
+                            escapeLabel(name), class.ChunkSize, formatValue(m.value(class)))
+            }
+        }
+    }
+    return bw.Flush()
+}
+
+// ServeHTTP writes metrics in Prometheus text format
+func (c *MetricsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+    c.WriteMetrics(w)
+}
+
+// write HELP and TYPE lines of metric
+func writeHeader(w io.Writer, name string, help string, typ string) {
+    fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+    fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+// escape label value
+func escapeLabel(value string) string {
+    return labelEscaper.Replace(value)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// format sample value
+func formatValue(value float64) string {
+    return strconv.FormatFloat(value, 'f', -1, 64)
+}
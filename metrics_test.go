@@ -0,0 +1,64 @@
+/* metrics_test.go - unit test for metrics.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "bytes"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestMetricsCollector(t *testing.T) {
+    pool1, _ := CreateSlabPool(4096, 512, 1024, 2)
+    pool2, _ := CreateSlabPool(4096, 1024, 1024, 2)
+    pool1.Get(1000)
+    pool1.Get(1000)
+    pool2.Get(100)
+
+    collector := NewMetricsCollector()
+    collector.Register("pool1", pool1)
+    collector.Register("pool\"2\"", pool2)
+
+    var buf bytes.Buffer
+    if err := collector.WriteMetrics(&buf); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    text := buf.String()
+    lines := []string{
+        "# TYPE slab_pool_class_gets_total counter\n",
+        "# TYPE slab_pool_class_chunks_in_use gauge\n",
+        "slab_pool_class_gets_total{pool=\"pool1\",chunk_size=\"512\"} 0\n",
+        "slab_pool_class_gets_total{pool=\"pool1\",chunk_size=\"1024\"} 2\n",
+        "slab_pool_class_requested_bytes{pool=\"pool\\\"2\\\"\",chunk_size=\"1024\"} 100\n",
+        "slab_pool_slab_bytes{pool=\"pool1\"} 4112\n",
+    }
+    for _, line := range lines {
+        if !strings.Contains(text, line) {
+            t.Errorf("metrics should contain %q", line)
+        }
+    }
+
+    // each metric has one header
+    if strings.Count(text, "# TYPE slab_pool_class_slabs ") != 1 {
+        t.Errorf("samples of a metric should be grouped")
+    }
+
+    // http handler
+    collector.Unregister("pool\"2\"")
+    w := httptest.NewRecorder()
+    collector.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+    if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+        t.Errorf("wrong content type: %s", w.Header().Get("Content-Type"))
+    }
+    if strings.Contains(w.Body.String(), "pool\\\"2\\\"") {
+        t.Errorf("pool unregistered should not be exposed")
+    }
+}
@@ -0,0 +1,38 @@
+/* mmap_linux.go - huge pages and populating pages on Linux */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, ignore EINVAL of MADV_HUGEPAGE
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "syscall"
+)
+
+const (
+    mapPopulate       = syscall.MAP_POPULATE
+    madvPopulateWrite = 23 // since Linux 5.14
+)
+
+// advise kernel to use transparent huge pages for memory
+func adviseHugePages(memory []byte) error {
+    err := syscall.Madvise(memory, syscall.MADV_HUGEPAGE)
+    if err == syscall.EINVAL {
+        // transparent huge pages not supported by kernel, advice is optional
+        return nil
+    }
+    return err
+}
+
+// populate pages of memory
+func populatePages(memory []byte) {
+    if syscall.Madvise(memory, madvPopulateWrite) != nil {
+        // not supported by kernel
+        touchPages(memory)
+    }
+}
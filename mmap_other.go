@@ -0,0 +1,26 @@
+//go:build !unix
+
+/* mmap_other.go - slab memory from mmap (unsupported platforms) */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "fmt"
+)
+
+// map anonymous memory for slab
+func mmapSlab(size int, hugePages bool, populate bool) ([]byte, error) {
+    return nil, fmt.Errorf("mmap is not supported")
+}
+
+// unmap memory of slab
+func munmapSlab(memory []byte) error {
+    return fmt.Errorf("mmap is not supported")
+}
@@ -0,0 +1,88 @@
+//go:build unix
+
+/* mmap_unix.go - slab memory from mmap */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, support huge pages and populating pages
+2026/10/17, by agent, move HUGE_PAGE_SIZE to memory_provider.go
+2026/10/17, by agent, keep huge page mapping without transparent huge pages
+*/
+/*
+DESCRIPTION
+    Slab memory mapped from anonymous memory is not managed by Go runtime,
+    so it is invisible to GC and does not count toward GOGC pacing.
+
+    For huge pages, a larger area is mapped so that slab memory could be
+    aligned to HUGE_PAGE_SIZE. The whole area is kept in hugeMappings, and
+    unmapped when the slab memory is unmapped.
+*/
+package slab_pool
+
+import (
+    "sync"
+    "syscall"
+    "unsafe"
+)
+
+// areas mapped for huge pages (start of slab memory => area mapped)
+var hugeMappings sync.Map
+
+// map anonymous memory for slab
+//
+// If 'hugePages' is true, slab memory is aligned to HUGE_PAGE_SIZE and
+// advised to use huge pages (if supported by kernel). If 'populate' is true, pages of slab memory
+// are populated up front.
+func mmapSlab(size int, hugePages bool, populate bool) ([]byte, error) {
+    if !hugePages {
+        flags := syscall.MAP_ANON | syscall.MAP_PRIVATE
+        if populate {
+            flags |= mapPopulate
+        }
+        memory, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, flags)
+        if err != nil {
+            return nil, err
+        }
+        if populate && mapPopulate == 0 {
+            touchPages(memory)
+        }
+        return memory, nil
+    }
+
+    // map a larger area, and align slab memory to huge page
+    area, err := syscall.Mmap(-1, 0, size+HUGE_PAGE_SIZE,
+        syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+    if err != nil {
+        return nil, err
+    }
+    addr := uintptr(unsafe.Pointer(&area[0]))
+    offset := int((HUGE_PAGE_SIZE - addr%HUGE_PAGE_SIZE) % HUGE_PAGE_SIZE)
+    memory := area[offset : offset+size : offset+size]
+
+    if err := adviseHugePages(memory); err != nil {
+        syscall.Munmap(area)
+        return nil, err
+    }
+    if populate {
+        populatePages(memory)
+    }
+    hugeMappings.Store(&memory[0], area)
+    return memory, nil
+}
+
+// unmap memory of slab
+func munmapSlab(memory []byte) error {
+    if area, ok := hugeMappings.LoadAndDelete(&memory[0]); ok {
+        return syscall.Munmap(area.([]byte))
+    }
+    return syscall.Munmap(memory)
+}
+
+// touch each page of memory
+func touchPages(memory []byte) {
+    pageSize := syscall.Getpagesize()
+    for i := 0; i < len(memory); i += pageSize {
+        memory[i] = 0
+    }
+}
@@ -0,0 +1,28 @@
+//go:build unix && !linux
+
+/* mmap_unix_other.go - huge pages and populating pages on other unix */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+    Transparent huge pages are not supported, and pages are populated by
+    touching them.
+*/
+package slab_pool
+
+const (
+    mapPopulate = 0 // not supported
+)
+
+// advise kernel to use transparent huge pages for memory
+func adviseHugePages(memory []byte) error {
+    return nil
+}
+
+// populate pages of memory
+func populatePages(memory []byte) {
+    touchPages(memory)
+}
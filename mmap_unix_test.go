@@ -0,0 +1,143 @@
+//go:build unix
+
+/* mmap_unix_test.go - unit test for mmap_unix.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "errors"
+    "testing"
+    "unsafe"
+)
+
+func TestMmapSlab(t *testing.T) {
+    slabPool, err := CreateSlabPoolWithOptions(4096, 64, 1024, 2, &Options{Mmap: true})
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    // allocate chunks from mmap memory
+    chunk1, _ := slabPool.Get(1000)
+    chunk2, _ := slabPool.Get(100)
+    for i := range chunk1 {
+        chunk1[i] = byte(i)
+    }
+    slab, _, _ := slabPool.locate(chunk1)
+    if _, ok := slab.slabClass.provider.(MmapProvider); !ok {
+        t.Errorf("slab memory should be from mmap")
+    }
+
+    // release chunks and unmap slabs
+    if slabPool.Put(chunk1) != nil || slabPool.Put(chunk2) != nil {
+        t.Errorf("unexpected error for chunk from mmap")
+    }
+    if count := slabPool.Shrink(0); count != 2 {
+        t.Errorf("2 slabs should be released, got %d", count)
+    }
+    if slabPool.table.lookup(chunk1) != nil {
+        t.Errorf("slab memory should be released")
+    }
+}
+
+func TestMmapArena(t *testing.T) {
+    options := &Options{ArenaSize: 1 << 20, Mmap: true}
+    slabPool, err := CreateSlabPoolWithOptions(4096, 64, 1024, 2, options)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    chunk, err := slabPool.Get(1000)
+    if err != nil || !slabPool.arena.contains(chunk) {
+        t.Errorf("should return chunk in arena")
+    }
+    if err := slabPool.Put(chunk); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+}
+
+func TestMmapClose(t *testing.T) {
+    // free slabs are unmapped
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 64, 1024, 2, &Options{Mmap: true})
+    chunk1 := mustGet(t, slabPool, 1000)
+    chunk2 := mustGet(t, slabPool, 100)
+    slabPool.Put(chunk1)
+    slabPool.Close()
+    if stats := slabPool.Stats(); stats.Slabs != 1 {
+        t.Errorf("only slab in use should be kept: %+v", stats)
+    }
+    if err := slabPool.Put(chunk2); err != nil {
+        t.Errorf("chunk should be valid after Close(): %s", err)
+    }
+
+    // arena is kept while chunks in use
+    options := &Options{ArenaSize: 1 << 20, Mmap: true}
+    slabPool, _ = CreateSlabPoolWithOptions(4096, 64, 1024, 2, options)
+    chunk1 = mustGet(t, slabPool, 1000)
+    slabPool.Close()
+    if slabPool.arena.arena == nil {
+        t.Errorf("arena should be kept while chunks in use")
+    }
+    slabPool.Put(chunk1)
+
+    // arena is unmapped
+    slabPool, _ = CreateSlabPoolWithOptions(4096, 64, 1024, 2, options)
+    slabPool.Put(mustGet(t, slabPool, 1000))
+    slabPool.Close()
+    if slabPool.arena.arena != nil {
+        t.Errorf("arena should be released")
+    }
+    if _, err := slabPool.Get(1000); !errors.Is(err, ErrPoolExhausted) {
+        t.Errorf("should return ErrPoolExhausted after Close(), got %v", err)
+    }
+}
+
+func TestMmapHugePages(t *testing.T) {
+    provider := MmapProvider{HugePages: true, Populate: true}
+    size := 4<<20 + SLAB_FOOTER_LEN
+
+    memory, err := provider.Alloc(size)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if len(memory) != size || cap(memory) != size {
+        t.Errorf("should return memory with size %d", size)
+    }
+    if uintptr(unsafe.Pointer(&memory[0]))%HUGE_PAGE_SIZE != 0 {
+        t.Errorf("memory should be aligned to huge page")
+    }
+    memory[size-1] = 1
+
+    provider.Free(memory)
+    if _, ok := hugeMappings.Load(&memory[0]); ok {
+        t.Errorf("area mapped should be removed")
+    }
+}
+
+func TestMmapHugePagesArena(t *testing.T) {
+    options := &Options{HugePages: true, Mmap: true, ArenaSize: 2 * HUGE_PAGE_SIZE}
+    slabPool, err := CreateSlabPoolWithOptions(4096, 1024, 4096, 2, options)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    chunk, err := slabPool.Get(4096)
+    if err != nil || !slabPool.arena.contains(chunk) {
+        t.Errorf("should return chunk in arena")
+    }
+    slabPool.Put(chunk)
+}
+
+func TestMmapPopulate(t *testing.T) {
+    options := &Options{Mmap: true, Populate: true}
+    slabPool, _ := CreateSlabPoolWithOptions(1<<20, 1024, 4096, 2, options)
+    chunk, err := slabPool.Get(4096)
+    if err != nil || len(chunk) != 4096 {
+        t.Errorf("should return valid chunk")
+    }
+    slabPool.Put(chunk)
+}
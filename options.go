@@ -0,0 +1,137 @@
+/* options.go - options for SlabPool */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, require the same slab size in arena mode
+2026/10/17, by agent, note Close() for memory from mmap
+*/
+/*
+DESCRIPTION
+    Options controls optional behaviors of SlabPool. The zero value of
+    Options gives the same pool as CreateSlabPool().
+*/
+package slab_pool
+
+import (
+    "time"
+)
+
+const (
+    EXHAUST_ERROR = 0 // return ErrPoolExhausted
+    EXHAUST_BLOCK = 1 // block until memory is freed
+    EXHAUST_HEAP  = 2 // fall back to Go heap allocation
+)
+
+type Options struct {
+    // Concurrent makes the pool safe for use by multiple goroutines.
+    // Each SlabClass is guarded by its own lock, so allocations in
+    // different size classes never contend.
+    Concurrent bool
+
+    // ShrinkPolicy enables background release of free slabs. Since the
+    // pool is then accessed by a background goroutine, it implies
+    // Concurrent. Call Close() to stop the background goroutine.
+    ShrinkPolicy *ShrinkPolicy
+
+    // MaxBytes is the max bytes of slab memory in the pool (0 for
+    // unlimited), including footers of slabs. When it is hit, free slabs
+    // of other SlabClasses are released for the memory.
+    MaxBytes int64
+
+    // MaxSlabsPerClass is the max count of slabs in each SlabClass (0 for
+    // unlimited).
+    MaxSlabsPerClass int
+
+    // ExhaustPolicy decides what Get() does when a limit is hit:
+    //   - EXHAUST_ERROR: return ErrPoolExhausted
+    //   - EXHAUST_BLOCK: block until memory is freed, see GetContext()
+    //   - EXHAUST_HEAP : return a chunk allocated from Go heap. It could be
+    //                    passed to Put()/IncRef()/DecRef() as usual, and is
+    //                    reclaimed by GC.
+    // Blocking implies Concurrent since memory is freed by other goroutines.
+    ExhaustPolicy int
+
+    // MemoryProvider provides memory of slabs (Go heap if nil). Memory of
+    // a slab is returned to the provider when the slab is released by
+    // shrinking.
+    //
+    // Note: chunks of released slabs must not be accessed. Put()/IncRef()/
+    // DecRef() on them return error.
+    MemoryProvider MemoryProvider
+
+    // Mmap is a shorthand for MemoryProvider: MmapProvider{}. Memory from
+    // mmap is not reclaimed by GC, so Close() the pool to unmap it.
+    Mmap bool
+
+    // HugePages and Populate apply to memory from Mmap (including the
+    // arena in arena mode), see MmapProvider. They need Mmap, and can not
+    // be used with MemoryProvider. With HugePages, slab memory (including
+    // footer) is rounded up to a multiple of HUGE_PAGE_SIZE, and ArenaSize
+    // should be a multiple of HUGE_PAGE_SIZE.
+    HugePages bool
+    Populate  bool
+
+    // ArenaSize enables arena mode. The whole capacity of the pool is
+    // reserved up front as one contiguous arena of ArenaSize bytes (from
+    // mmap if Mmap is set), and slabs are carved from it. No further memory
+    // is requested for slabs after the pool is created. It can not be used
+    // with MemoryProvider, and MaxBytes is limited to ArenaSize. All slab
+    // classes should have the same slab size, so memory of slabs released
+    // is reused by any slab class. The arena is released by Close().
+    ArenaSize int
+
+    // ArenaEager carves the whole arena into slabs and touches its pages
+    // when the pool is created, instead of on demand.
+    ArenaEager bool
+
+    // Debug enables memory poisoning and canaries for finding memory bugs:
+    // chunks are poisoned when freed and checked when allocated again, and
+    // canary bytes after each chunk are checked by Put()/DecRef(). Wrong
+    // writes are reported by *CorruptionError. It slows down the pool, and
+    // slabSize should be no less than chunkSizeMax+CHUNK_CANARY_LEN.
+    Debug bool
+
+    // TrackAllocations records the stack of caller for each chunk
+    // allocated, see LeakReport(). It slows down allocations.
+    TrackAllocations bool
+
+    // RecordSizes records sizes requested in a histogram, see
+    // SizeHistogram() and AdviseClasses().
+    RecordSizes bool
+
+    // SlabWaste enables automatic slab size of each slab class. Slab size
+    // is chosen so that the unused tail of slab is no more than SlabWaste
+    // (e.g. 0.05) of slab memory, and slab memory is a multiple of
+    // SLAB_SIZE_UNIT. Slab sizes given by ClassConfig are kept. Slab sizes
+    // chosen are reported by Stats().
+    SlabWaste float64
+
+    // LargeAlloc serves sizes above chunkSizeMax by Get(), each by a
+    // dedicated slab of a single chunk, instead of returning error. Large
+    // chunks could be passed to Put()/IncRef()/DecRef() as usual, and
+    // their memory is freed when released. They are counted in MaxBytes
+    // (except in arena mode), follow EXHAUST_HEAP but never block, and are
+    // reported separately by Stats(). See large_alloc.go.
+    LargeAlloc bool
+}
+
+type ClassConfig struct {
+    // ChunkSize is the chunk size of slab class (bytes)
+    ChunkSize int
+
+    // SlabSize is the slab size of slab class (bytes). If 0, slab size is
+    // chosen by Options.SlabWaste, or default slab size of pool is used.
+    SlabSize int
+}
+
+type ShrinkPolicy struct {
+    // KeepFree is the number of free slabs kept in each SlabClass
+    KeepFree int
+
+    // IdleTimeout is how long a slab should stay free before released
+    IdleTimeout time.Duration
+
+    // Interval is the period for checking free slabs (IdleTimeout/2 if 0)
+    Interval time.Duration
+}
@@ -0,0 +1,258 @@
+/* size_advisor.go - advisor of slab classes for sizes requested */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+    AdviseClasses() finds slab classes for the workload recorded in size
+    histogram (see Options.RecordSizes), in two forms:
+    - chunkSizeMin/chunkSizeMax/factor for CreateSlabPool(), found by
+      trying factors from ADVISE_FACTOR_MIN to ADVISE_FACTOR_MAX
+    - an explicit list of chunk sizes, found by dynamic programming over
+      buckets of histogram
+
+    Both forms have at most 'maxClasses' slab classes. They are compared
+    with the current slab classes by bytes handed out for the requests
+    recorded, i.e. sum of chunk sizes. A request is assumed to be of the
+    max size in its bucket, so the estimation is conservative.
+
+Usage:
+    slabPool, err := CreateSlabPoolWithOptions(4096, 128, 1024, 2,
+            &Options{RecordSizes: true})
+    ...
+    advice := slabPool.AdviseClasses(0)
+    log.Printf("%s", advice)
+*/
+package slab_pool
+
+import (
+    "bytes"
+    "fmt"
+    "math"
+)
+
+const (
+    ADVISE_FACTOR_MIN  = 1.05 // min factor tried
+    ADVISE_FACTOR_MAX  = 4.0  // max factor tried
+    ADVISE_FACTOR_STEP = 0.01 // step of factors tried
+)
+
+// ClassAdvice is the result of AdviseClasses()
+type ClassAdvice struct {
+    Requests       uint64  // count of requests recorded
+    RequestedBytes int64   // bytes requested (including oversize)
+    Oversize       uint64  // requests larger than slab size (not counted below)
+
+    CurrentBytes   int64   // bytes handed out by current slab classes (requests
+                           // larger than chunkSizeMax are counted by their sizes)
+
+    ChunkSizeMin   int     // advised params for CreateSlabPool()
+    ChunkSizeMax   int
+    Factor         float64
+    FactorBytes    int64   // bytes handed out with advised params
+
+    Classes        []int   // advised chunk sizes, for CreateSlabPoolWithClasses()
+    ClassesBytes   int64   // bytes handed out with advised chunk sizes
+}
+
+/* AdviseClasses - advise slab classes for sizes requested
+ *
+ * Params:
+ *     - maxClasses: max count of slab classes (count of current slab
+ *                   classes if 0)
+ *
+ * Return:
+ *     - advice (nil if RecordSizes is not enabled, or no request recorded)
+ */
+func (sp *SlabPool) AdviseClasses(maxClasses int) *ClassAdvice {
+    if sp.sizes == nil {
+        return nil
+    }
+    if maxClasses <= 0 {
+        maxClasses = len(sp.slabClasses)
+    }
+
+    // sizes (max size of bucket) and counts of requests, limited by slab size
+    advice := new(ClassAdvice)
+    sizes := make([]int, 0)
+    counts := make([]uint64, 0)
+    for _, b := range sp.sizes.snapshot() {
+        advice.Requests += b.Count
+        if b.Min > sp.slabSize {
+            advice.Oversize += b.Count
+            continue
+        }
+        size := b.Max
+        if size > sp.slabSize {
+            size = sp.slabSize
+        }
+        sizes = append(sizes, size)
+        counts = append(counts, b.Count)
+    }
+    advice.RequestedBytes = sp.sizes.requested()
+    if len(sizes) == 0 {
+        return nil
+    }
+
+    current := make([]int, len(sp.slabClasses))
+    for i, slabClass := range sp.slabClasses {
+        current[i] = slabClass.chunkSize
+    }
+    advice.CurrentBytes = classesCost(current, sizes, counts)
+    advice.adviseFactor(sizes, counts, maxClasses, sp.slabSize)
+    advice.adviseClasses(sizes, counts, maxClasses)
+    return advice
+}
+
+// find chunkSizeMin/chunkSizeMax/factor with least bytes handed out
+func (a *ClassAdvice) adviseFactor(sizes []int, counts []uint64, maxClasses int,
+    slabSize int) {
+    a.FactorBytes = math.MaxInt64
+    minSize := sizes[0]
+    maxSize := sizes[len(sizes)-1]
+
+    steps := int(math.Round((ADVISE_FACTOR_MAX - ADVISE_FACTOR_MIN) / ADVISE_FACTOR_STEP))
+    for i := 0; i <= steps; i++ {
+        factor := math.Round((ADVISE_FACTOR_MIN+float64(i)*ADVISE_FACTOR_STEP)*100) / 100
+        if int(float64(minSize)*factor) == minSize {
+            continue
+        }
+
+        // generate classes as SlabPool does, until maxSize is covered
+        classes := make([]int, 0, maxClasses)
+        for chunkSize := minSize; len(classes) < maxClasses; {
+            classes = append(classes, chunkSize)
+            if chunkSize >= maxSize {
+                break
+            }
+            chunkSize = int(float64(chunkSize) * factor)
+        }
+        last := classes[len(classes)-1]
+        if last < maxSize || last > slabSize {
+            continue
+        }
+
+        cost := classesCost(classes, sizes, counts)
+        if cost < a.FactorBytes {
+            a.ChunkSizeMin = minSize
+            a.ChunkSizeMax = last
+            a.Factor = factor
+            a.FactorBytes = cost
+        }
+    }
+    if a.FactorBytes == math.MaxInt64 {
+        // no factor found, with maxClasses too small
+        a.FactorBytes = 0
+    }
+}
+
+// find chunk sizes with least bytes handed out, by dynamic programming:
+// cost[j][i] is the least cost for sizes[0..i] by j+1 classes, and the
+// largest class is sizes[i]
+func (a *ClassAdvice) adviseClasses(sizes []int, counts []uint64, maxClasses int) {
+    n := len(sizes)
+    if maxClasses > n {
+        maxClasses = n
+    }
+
+    // prefix sums of counts
+    sums := make([]uint64, n+1)
+    for i, count := range counts {
+        sums[i+1] = sums[i] + count
+    }
+
+    cost := make([][]int64, maxClasses)
+    prev := make([][]int, maxClasses)
+    for j := range cost {
+        cost[j] = make([]int64, n)
+        prev[j] = make([]int, n)
+        for i := range cost[j] {
+            cost[j][i] = math.MaxInt64
+            prev[j][i] = -1
+            if j == 0 {
+                cost[j][i] = int64(sums[i+1]) * int64(sizes[i])
+                continue
+            }
+            for p := j - 1; p < i; p++ {
+                if cost[j-1][p] == math.MaxInt64 {
+                    continue
+                }
+                c := cost[j-1][p] + int64(sums[i+1]-sums[p+1])*int64(sizes[i])
+                if c < cost[j][i] {
+                    cost[j][i] = c
+                    prev[j][i] = p
+                }
+            }
+        }
+    }
+
+    // more classes never cost more, find the least one
+    best := 0
+    for j := range cost {
+        if cost[j][n-1] < cost[best][n-1] {
+            best = j
+        }
+    }
+    a.ClassesBytes = cost[best][n-1]
+    a.Classes = make([]int, best+1)
+    for j, i := best, n-1; j >= 0; j-- {
+        a.Classes[j] = sizes[i]
+        i = prev[j][i]
+    }
+}
+
+// bytes handed out by 'classes' for requests of 'sizes'
+func classesCost(classes []int, sizes []int, counts []uint64) int64 {
+    var cost int64
+    j := 0
+    for i, size := range sizes {
+        for j < len(classes) && classes[j] < size {
+            j++
+        }
+        if j < len(classes) {
+            cost += int64(counts[i]) * int64(classes[j])
+        } else {
+            cost += int64(counts[i]) * int64(size)
+        }
+    }
+    return cost
+}
+
+// savings of bytes handed out by 'bytes', compared with current classes
+func (a *ClassAdvice) savings(bytes int64) float64 {
+    if a.CurrentBytes == 0 {
+        return 0
+    }
+    return 1 - float64(bytes)/float64(a.CurrentBytes)
+}
+
+// FactorSavings returns ratio of bytes saved by advised params
+func (a *ClassAdvice) FactorSavings() float64 {
+    if a.FactorBytes == 0 {
+        return 0
+    }
+    return a.savings(a.FactorBytes)
+}
+
+// ClassesSavings returns ratio of bytes saved by advised chunk sizes
+func (a *ClassAdvice) ClassesSavings() float64 {
+    return a.savings(a.ClassesBytes)
+}
+
+// String returns a report of advice
+func (a *ClassAdvice) String() string {
+    var buf bytes.Buffer
+    fmt.Fprintf(&buf, "requests: %d, requested bytes: %d, oversize: %d\n",
+                a.Requests, a.RequestedBytes, a.Oversize)
+    fmt.Fprintf(&buf, "current classes: %d bytes\n", a.CurrentBytes)
+    if a.FactorBytes > 0 {
+        fmt.Fprintf(&buf, "chunkSizeMin %d, chunkSizeMax %d, factor %.2f: "+
+                    "%d bytes (%.2f%% saved)\n", a.ChunkSizeMin, a.ChunkSizeMax,
+                    a.Factor, a.FactorBytes, a.FactorSavings()*100)
+    }
+    fmt.Fprintf(&buf, "classes %v: %d bytes (%.2f%% saved)\n",
+                a.Classes, a.ClassesBytes, a.ClassesSavings()*100)
+    return buf.String()
+}
@@ -0,0 +1,71 @@
+/* size_advisor_test.go - unit test for size_advisor.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "testing"
+)
+
+func TestAdviseClasses(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2,
+                                             &Options{RecordSizes: true})
+    if slabPool.AdviseClasses(0) != nil {
+        t.Errorf("should return nil without requests")
+    }
+    record := func(size int, count int) {
+        for i := 0; i < count; i++ {
+            chunk, _ := slabPool.Get(size)
+            slabPool.Put(chunk)
+        }
+    }
+    record(100, 100)
+    record(300, 100)
+    record(700, 50)
+
+    // classes 128, 256, 512, 1024
+    advice := slabPool.AdviseClasses(0)
+    if advice.Requests != 250 || advice.RequestedBytes != 100*100+300*100+700*50 ||
+       advice.CurrentBytes != 100*128+100*512+50*1024 {
+        t.Errorf("wrong advice: %+v", advice)
+    }
+
+    // explicit classes fit buckets [100, 103], [288, 303], [672, 703]
+    if len(advice.Classes) != 3 || advice.Classes[0] != 103 ||
+       advice.Classes[1] != 303 || advice.Classes[2] != 703 ||
+       advice.ClassesBytes != 100*103+100*303+50*703 {
+        t.Errorf("wrong classes: %v, %d bytes", advice.Classes, advice.ClassesBytes)
+    }
+    if s := advice.ClassesSavings(); s < 0.34 || s > 0.35 {
+        t.Errorf("wrong savings: %f", s)
+    }
+
+    // params for CreateSlabPool()
+    if advice.ChunkSizeMin != 103 || advice.ChunkSizeMax < 703 ||
+       advice.FactorBytes >= advice.CurrentBytes || advice.FactorSavings() <= 0 {
+        t.Errorf("wrong params: %+v", advice)
+    }
+    pool, err := CreateSlabPool(4096, advice.ChunkSizeMin, advice.ChunkSizeMax,
+                                advice.Factor)
+    if err != nil || len(pool.slabClasses) > 4 {
+        t.Errorf("advised params should be valid: %v", err)
+    }
+    if c := pool.slabClasses[len(pool.slabClasses)-1].chunkSize; c != advice.ChunkSizeMax {
+        t.Errorf("largest class should be chunkSizeMax, got %d", c)
+    }
+
+    // less classes
+    advice = slabPool.AdviseClasses(2)
+    if len(advice.Classes) != 2 || advice.Classes[1] != 703 {
+        t.Errorf("wrong classes: %v", advice.Classes)
+    }
+    if advice.ClassesBytes != 100*303+100*303+50*703 {
+        t.Errorf("wrong bytes: %d", advice.ClassesBytes)
+    }
+}
@@ -0,0 +1,107 @@
+/* size_histogram.go - histogram of sizes requested */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, record sizes of Grow()
+*/
+/*
+DESCRIPTION
+    With Options.RecordSizes, sizes passed to Get()/GetContext()/
+    ChunkCache.Get(), and sizes of chunks moved by Grow(), are recorded in a
+    log-linear histogram: sizes below SIZE_SUB_BUCKETS are counted exactly,
+    and each power of two above is split into SIZE_SUB_BUCKETS buckets. So
+    the width of a bucket is at most 1/SIZE_SUB_BUCKETS of its sizes.
+
+    Buckets are counted atomically, without any lock. The histogram is the
+    input of AdviseClasses().
+*/
+package slab_pool
+
+import (
+    "math/bits"
+    "sync/atomic"
+)
+
+const (
+    SIZE_SUB_BUCKETS = 16 // buckets in each power of two
+
+    sizeSubBits    = 4                    // log2(SIZE_SUB_BUCKETS)
+    sizeBucketsLen = (64 - sizeSubBits) * SIZE_SUB_BUCKETS
+)
+
+// SizeBucket is a bucket of sizes requested
+type SizeBucket struct {
+    Min   int    // min size in bucket
+    Max   int    // max size in bucket
+    Count uint64 // count of requests
+}
+
+type sizeHistogram struct {
+    buckets [sizeBucketsLen]uint64 // count of requests (accessed atomically)
+    bytes   int64                  // bytes requested (accessed atomically)
+}
+
+// record size requested
+func (h *sizeHistogram) record(size int) {
+    if size <= 0 {
+        return
+    }
+    atomic.AddUint64(&h.buckets[sizeBucket(size)], 1)
+    atomic.AddInt64(&h.bytes, int64(size))
+}
+
+// return non-empty buckets, in ascending order of sizes
+func (h *sizeHistogram) snapshot() []SizeBucket {
+    buckets := make([]SizeBucket, 0)
+    for i := range h.buckets {
+        count := atomic.LoadUint64(&h.buckets[i])
+        if count == 0 {
+            continue
+        }
+        min, max := bucketRange(i)
+        buckets = append(buckets, SizeBucket{Min: min, Max: max, Count: count})
+    }
+    return buckets
+}
+
+// return bytes requested
+func (h *sizeHistogram) requested() int64 {
+    return atomic.LoadInt64(&h.bytes)
+}
+
+// index of bucket for size
+func sizeBucket(size int) int {
+    if size < SIZE_SUB_BUCKETS {
+        return size
+    }
+    // 2^k <= size < 2^(k+1)
+    k := bits.Len(uint(size)) - 1
+    sub := (size >> (k - sizeSubBits)) & (SIZE_SUB_BUCKETS - 1)
+    return (k-sizeSubBits+1)*SIZE_SUB_BUCKETS + sub
+}
+
+// range of sizes in bucket
+func bucketRange(index int) (int, int) {
+    if index < SIZE_SUB_BUCKETS {
+        return index, index
+    }
+    k := index/SIZE_SUB_BUCKETS + sizeSubBits - 1
+    sub := index % SIZE_SUB_BUCKETS
+    width := 1 << (k - sizeSubBits)
+    min := (SIZE_SUB_BUCKETS + sub) * width
+    return min, min + width - 1
+}
+
+/* SizeHistogram - get histogram of sizes requested
+ *
+ * Return:
+ *     - non-empty buckets in ascending order of sizes (nil if RecordSizes
+ *       is not enabled)
+ */
+func (sp *SlabPool) SizeHistogram() []SizeBucket {
+    if sp.sizes == nil {
+        return nil
+    }
+    return sp.sizes.snapshot()
+}
@@ -0,0 +1,69 @@
+/* size_histogram_test.go - unit test for size_histogram.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "testing"
+)
+
+func TestSizeBucket(t *testing.T) {
+    prev := 0
+    for size := 1; size < 1<<20; size++ {
+        index := sizeBucket(size)
+        min, max := bucketRange(index)
+        if size < min || size > max {
+            t.Fatalf("size %d should be in bucket [%d, %d]", size, min, max)
+        }
+        if (max-min+1)*SIZE_SUB_BUCKETS > min && max != min {
+            t.Fatalf("bucket [%d, %d] is too wide", min, max)
+        }
+        if index < prev || index > prev+1 {
+            t.Fatalf("buckets should be continuous, size %d", size)
+        }
+        prev = index
+    }
+    if index := sizeBucket(int(^uint(0) >> 1)); index >= sizeBucketsLen {
+        t.Errorf("bucket of max int out of range: %d", index)
+    }
+}
+
+func TestSizeHistogram(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 128, 1024, 2)
+    if slabPool.SizeHistogram() != nil {
+        t.Errorf("should return nil without RecordSizes")
+    }
+
+    slabPool, _ = CreateSlabPoolWithOptions(4096, 128, 1024, 2,
+                                            &Options{RecordSizes: true})
+    for i := 0; i < 3; i++ {
+        slabPool.Get(10)
+    }
+    slabPool.Get(1000)
+    slabPool.Get(2000) // illegal size is recorded too
+    cache := slabPool.NewChunkCache(nil)
+    cache.Get(1001)
+
+    buckets := slabPool.SizeHistogram()
+    if len(buckets) != 3 {
+        t.Fatalf("should have 3 buckets, got %v", buckets)
+    }
+    if buckets[0] != (SizeBucket{Min: 10, Max: 10, Count: 3}) {
+        t.Errorf("wrong bucket: %+v", buckets[0])
+    }
+    if buckets[1] != (SizeBucket{Min: 992, Max: 1023, Count: 2}) {
+        t.Errorf("wrong bucket: %+v", buckets[1])
+    }
+    if buckets[2].Min > 2000 || buckets[2].Max < 2000 || buckets[2].Count != 1 {
+        t.Errorf("wrong bucket: %+v", buckets[2])
+    }
+    if slabPool.sizes.requested() != 30+1000+2000+1001 {
+        t.Errorf("wrong bytes requested: %d", slabPool.sizes.requested())
+    }
+}
@@ -3,6 +3,14 @@
 modification history
 --------------------
 2014/12/2, by Sijie Yang, create
+2026/10/17, by agent, support slab release
+2026/10/17, by agent, support slab memory from mmap
+2026/10/17, by agent, slab memory is provided by SlabClass
+2026/10/17, by agent, write slab ID instead of slab pointer into footer
+2026/10/17, by agent, return errors for wrong reference operations
+2026/10/17, by agent, support debug mode
+2026/10/17, by agent, mark dedicated slabs of large chunks
+2026/10/17, by agent, keep slice headers of slab released
 */
 /*
 DESCRIPTION
@@ -11,43 +19,58 @@ package slab_pool
 
 import (
     "encoding/binary"
-    "unsafe"
+    "time"
 )
 
 const SLAB_FOOTER_LEN int = 16
 
 type Slab struct {
-    slabSize   int         // slab size
-    chunkSize  int         // chunk size
-    slabMagic  uint64      // magic number for slab footer
+    id          uint64      // slab ID (unique in pool)
+    slabSize    int         // slab size
+    chunkSize   int         // chunk size
+    chunkStride int         // distance between chunks (with canary in debug mode)
+    debug       bool        // poison free chunks and check canaries
+    slabMagic   uint64      // magic number for slab footer
 
-    memory     []byte      // slab memory area
-    chunkInfo  []ChunkInfo // chunk info
-    chunkFree  int         // head of chunk free list
+    memory      []byte      // slab memory area
+    chunkInfo   []ChunkInfo // chunk info
+    chunkFree   int         // head of chunk free list
 
-    countChunk int         // count of chunks in this slab
-    countFree  int         // count of free chunks in this slab
+    countChunk  int         // count of chunks in this slab
+    countFree   int         // count of free chunks in this slab
 
     /* management info in its slabClass */
-    slabClass  *SlabClass  // link to its slabClass
-    index      int         // slab index of slabClass.slabs
-    whichList  int         // in which slablist (SLAB_FREE/SLAB_USE/SLAB_FULL)
-    prev       int         // prev node in slablist
-    next       int         // next node in slablist
+    slabClass   *SlabClass  // link to its slabClass
+    large       bool        // dedicated slab of a large chunk (no slabClass)
+    index       int         // slab index of slabClass.slabs
+    whichList   int         // in which slablist (SLAB_FREE/SLAB_USE/SLAB_FULL)
+    prev        int         // prev node in slablist
+    next        int         // next node in slablist
+    freeSince   time.Time   // when slab becomes SLAB_FREE
 }
 
+// create slab with memory from Go heap
 func NewSlab(sc *SlabClass, slabSize int, chunkSize int, slabMagic uint64) *Slab {
+    memory := make([]byte, slabSize+SLAB_FOOTER_LEN)
+    return newSlab(sc, memory, chunkSize, slabMagic)
+}
+
+// create slab with memory (size of memory: slabSize+SLAB_FOOTER_LEN)
+func newSlab(sc *SlabClass, memory []byte, chunkSize int, slabMagic uint64) *Slab {
     s := new(Slab)
     s.slabClass = sc
-    s.slabSize = slabSize
+    s.slabSize = len(memory) - SLAB_FOOTER_LEN
     s.chunkSize = chunkSize
     s.slabMagic = slabMagic
-
-    // TODO(yangsijie): alloc slab memory from pool
-    s.memory = make([]byte, slabSize+SLAB_FOOTER_LEN)
+    s.memory = memory
+    s.chunkStride = chunkSize
+    if sc != nil && sc.debug {
+        s.debug = true
+        s.chunkStride = chunkSize + CHUNK_CANARY_LEN
+    }
 
     // initial chunk info
-    s.countChunk = slabSize / chunkSize
+    s.countChunk = s.slabSize / s.chunkStride
     s.countFree = s.countChunk
     s.chunkInfo = make([]ChunkInfo, s.countChunk)
     s.chunkFree = 0
@@ -60,6 +83,9 @@ func NewSlab(sc *SlabClass, slabSize int, chunkSize int, slabMagic uint64) *Slab
     s.next = -1
 
     s.initFooter()
+    if s.debug {
+        s.initDebug()
+    }
     return s
 }
 
@@ -68,47 +94,96 @@ func (s *Slab) initChunkInfo() {
     var i = 0
     for ; i < len(s.chunkInfo)-1; i++ {
         s.chunkInfo[i].next = i + 1
+        s.chunkInfo[i].refs = CHUNK_UNUSED
     }
     s.chunkInfo[i].next = -1
+    s.chunkInfo[i].refs = CHUNK_UNUSED
 }
 
 // initial footer info
 func (s *Slab) initFooter() {
     footer := s.memory[s.slabSize:]
-    slabPtr := uintptr(unsafe.Pointer(s))
-    binary.BigEndian.PutUint64(footer[0:8], s.slabMagic)      // slab magic
-    binary.BigEndian.PutUint64(footer[8:16], uint64(slabPtr)) // slab pointer
+    binary.BigEndian.PutUint64(footer[0:8], s.slabMagic) // slab magic
+    binary.BigEndian.PutUint64(footer[8:16], s.id)       // slab ID
+}
+
+// check footer info
+func (s *Slab) checkFooter() bool {
+    footer := s.memory[s.slabSize:]
+    return binary.BigEndian.Uint64(footer[0:8]) == s.slabMagic &&
+           binary.BigEndian.Uint64(footer[8:16]) == s.id
+}
+
+// release slab, return slab memory
+//
+// Slice headers of memory and chunkInfo are kept, since they may be read
+// without lock: locate() of a stale chunk looked up just before the slab
+// is unregistered, or LeakReport() on slabs without slab class
+func (s *Slab) release() []byte {
+    // clear footer, so that stale chunks are not accepted any more
+    footer := s.memory[s.slabSize:]
+    for i := range footer {
+        footer[i] = 0
+    }
+    return s.memory
 }
 
 // allocate chunk
 func (s *Slab) chunkAlloc() []byte {
-    if s.countFree <= 0 {
+    index := s.chunkAllocIndex()
+    if index < 0 {
         return nil
     }
+    return s.chunk(index)
+}
+
+// allocate chunk, return index of chunk (-1 if no free chunk)
+func (s *Slab) chunkAllocIndex() int {
+    if s.countFree <= 0 {
+        return -1
+    }
 
     // remove head chunk from free list
     head := s.chunkFree
     s.chunkFree = s.chunkInfo[head].next
     s.chunkInfo[head].next = -1
-    s.chunkInfo[head].refs = 1
+    s.chunkInfo[head].setRef(1)
     s.countFree -= 1
 
-    // return chunk
-    return s.memory[s.chunkSize*head : s.chunkSize*(head+1)]
+    return head
+}
+
+// get chunk by index
+func (s *Slab) chunk(index int) []byte {
+    start := s.chunkStride * index
+    return s.memory[start : start+s.chunkSize]
 }
 
 // increase refs for chunk
-func (s *Slab) chunkIncRef(index int) {
-    s.chunkInfo[index].incRef()
+func (s *Slab) chunkIncRef(index int) error {
+    _, err := s.chunkInfo[index].incRef()
+    return err
 }
 
-// decrease refs for chunk
-func (s *Slab) chunkDecRef(index int) {
-    // add chunk to free list
-    if s.chunkInfo[index].decRef() == 0 {
-        s.chunkInfo[index].next = s.chunkFree
-        s.chunkFree = index
+// decrease refs for chunk, return true if refs drops to zero
+func (s *Slab) chunkDecRef(index int) (bool, error) {
+    if s.debug {
+        if err := s.checkCanary(index); err != nil {
+            return false, err
+        }
+    }
+    refs, err := s.chunkInfo[index].decRef()
+    released := err == nil && refs == 0
+    if released && s.debug {
+        s.chunkPoison(index)
     }
+    return released, err
+}
+
+// add chunk to free list
+func (s *Slab) chunkRelease(index int) {
+    s.chunkInfo[index].next = s.chunkFree
+    s.chunkFree = index
     s.countFree += 1
 }
 
@@ -3,25 +3,69 @@
 modification history
 --------------------
 2014/12/2, by Sijie Yang, create
+2026/10/17, by agent, add per-class locking for concurrent mode
+2026/10/17, by agent, change chunk refs without lock
+2026/10/17, by agent, add slabShrink()
+2026/10/17, by agent, support memory limits
+2026/10/17, by agent, hand off chunks to waiters
+2026/10/17, by agent, support slab memory from mmap
+2026/10/17, by agent, alloc slab memory from MemoryProvider
+2026/10/17, by agent, register slabs in slabTable
+2026/10/17, by agent, return errors for wrong reference operations
+2026/10/17, by agent, check chunks allocated in debug mode
+2026/10/17, by agent, add statistics
+2026/10/17, by agent, add slabReclaim() and notify when a slab becomes free
+2026/10/17, by agent, count waiters atomically
+2026/10/17, by agent, check size of memory from provider
 */
 /*
 DESCRIPTION
 */
 package slab_pool
 
+import (
+    "container/list"
+    "fmt"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
 const (
     SLAB_FREE = 0 // slab with no chunk allocated
     SLAB_USE  = 1 // slab with some chunk allocated
     SLAB_FULL = 2 // slab with all chunk allocated
 )
 
+// location of a chunk
+type chunkRef struct {
+    slab  *Slab // slab of chunk
+    index int   // chunk index in slab
+}
+
 type SlabClass struct {
-    slabSize     int      // slab size
-    chunkSize    int      // chunk size
-    slabMagic    uint64   // magic number for slab
+    counters     classCounters // statistics (first for alignment of atomics)
+
+    index        int        // index in slab classes of pool
+    slabSize     int        // slab size
+    chunkSize    int        // chunk size
+    slabMagic    uint64     // magic number for slab
 
-    slabs        []*Slab  // all slabs
-    slabLists[3] int      // head of slab lists(SLAB_FREE/SLAB_USE/SLAB_FULL)
+    slabs        []*Slab    // all slabs
+    slabLists[3] int        // head of slab lists(SLAB_FREE/SLAB_USE/SLAB_FULL)
+    listLen[3]   int        // count of slabs in each slab list
+
+    provider     MemoryProvider // provider of slab memory (nil for Go heap)
+    maxSlabs     int        // max count of slabs (0 for unlimited)
+    table        *slabTable // index of slabs shared by slab classes
+    limit        *memLimit  // memory limit shared by slab classes (may be nil)
+    freed        *notifier  // notified when slab memory is freed (may be nil)
+    waiters      list.List  // goroutines waiting for chunks (*chunkWaiter)
+    waiting      int32      // length of waiters (accessed atomically)
+
+    debug        bool       // poison free chunks and check canaries
+    concurrent   bool       // whether lock is used
+    mutex        sync.Mutex // protect slabs, slabLists and chunk free lists
 }
 
 func NewSlabClass(slabSize int, chunkSize int, slabMagic uint64) *SlabClass {
@@ -35,39 +79,219 @@ func NewSlabClass(slabSize int, chunkSize int, slabMagic uint64) *SlabClass {
     sc.slabLists[SLAB_FREE] = -1
     sc.slabLists[SLAB_USE] = -1
     sc.slabLists[SLAB_FULL] = -1
+    sc.table = newSlabTable()
 
     return sc
 }
 
 // allocate slab
-func (sc *SlabClass) slabAlloc() *Slab {
-    slab := NewSlab(sc, sc.slabSize, sc.chunkSize, sc.slabMagic)
+func (sc *SlabClass) slabAlloc() (*Slab, error) {
+    // check memory limits
+    if sc.maxSlabs > 0 && len(sc.slabs) >= sc.maxSlabs {
+        return nil, ErrPoolExhausted
+    }
+    if sc.limit != nil && !sc.limit.reserve(sc.slabMemSize()) {
+        return nil, ErrPoolExhausted
+    }
+
+    var slab *Slab
+    if sc.provider != nil {
+        memory, err := providerAlloc(sc.provider, sc.slabSize+SLAB_FOOTER_LEN)
+        if err != nil {
+            if sc.limit != nil {
+                sc.limit.release(sc.slabMemSize())
+            }
+            return nil, fmt.Errorf("alloc slab memory: %w", err)
+        }
+        slab = newSlab(sc, memory, sc.chunkSize, sc.slabMagic)
+    } else {
+        slab = NewSlab(sc, sc.slabSize, sc.chunkSize, sc.slabMagic)
+    }
     sc.slabs = append(sc.slabs, slab)
     slab.index = len(sc.slabs) - 1
-    return slab
+    sc.table.register(slab)
+    atomic.AddUint64(&sc.counters.slabAllocs, 1)
+    return slab, nil
+}
+
+// memory size of a slab
+func (sc *SlabClass) slabMemSize() int64 {
+    return int64(sc.slabSize + SLAB_FOOTER_LEN)
+}
+
+// release free slabs, return count of slabs released
+//
+// At most 'keepFree' free slabs are kept, and only slabs which become free
+// before 'idleBefore' are released (zero 'idleBefore' for all free slabs)
+func (sc *SlabClass) slabShrink(keepFree int, idleBefore time.Time) int {
+    sc.lock()
+    defer sc.unlock()
+
+    // find slabs to release (recently freed slabs are at head of list)
+    released := make([]*Slab, 0)
+    countFree := 0
+    for node := sc.slabLists[SLAB_FREE]; node >= 0; node = sc.slabs[node].next {
+        slab := sc.slabs[node]
+        countFree++
+        if countFree <= keepFree {
+            continue
+        }
+        if idleBefore.IsZero() || slab.freeSince.Before(idleBefore) {
+            released = append(released, slab)
+        }
+    }
+
+    // release slabs
+    for _, slab := range released {
+        sc.slabRelease(slab)
+    }
+    if len(released) > 0 && sc.freed != nil {
+        sc.freed.notify()
+    }
+    return len(released)
+}
+
+// release free slabs for memory of at least 'size' bytes (fewer if not
+// enough free slabs), return count of slabs released. Unlike slabShrink(),
+// no free slab is kept, since the memory is needed by other slab classes
+func (sc *SlabClass) slabReclaim(size int64) int {
+    sc.lock()
+    defer sc.unlock()
+
+    count := 0
+    for released := int64(0); released < size && !sc.listEmpty(SLAB_FREE); count++ {
+        sc.slabRelease(sc.slabs[sc.slabLists[SLAB_FREE]])
+        released += sc.slabMemSize()
+    }
+    if count > 0 && sc.freed != nil {
+        sc.freed.notify()
+    }
+    return count
+}
+
+// remove slab from slab class and release it (caller should hold the lock)
+func (sc *SlabClass) slabRelease(slab *Slab) {
+    sc.listRemove(slab.whichList, slab.index)
+
+    // move the last slab to position of released slab
+    last := len(sc.slabs) - 1
+    if slab.index != last {
+        sc.slabRelocate(sc.slabs[last], slab.index)
+    }
+    sc.slabs[last] = nil
+    sc.slabs = sc.slabs[:last]
+
+    slab.index = -1
+    sc.table.unregister(slab)
+    memory := slab.release()
+    if sc.provider != nil {
+        sc.provider.Free(memory)
+    }
+    if sc.limit != nil {
+        sc.limit.release(sc.slabMemSize())
+    }
+    atomic.AddUint64(&sc.counters.slabReleases, 1)
+}
+
+// move slab to position 'index' of sc.slabs, and update its slablist
+func (sc *SlabClass) slabRelocate(slab *Slab, index int) {
+    if slab.prev >= 0 {
+        sc.slabs[slab.prev].next = index
+    } else {
+        sc.slabLists[slab.whichList] = index
+    }
+    if slab.next >= 0 {
+        sc.slabs[slab.next].prev = index
+    }
+    sc.slabs[index] = slab
+    slab.index = index
+}
+
+// lock slab class (only in concurrent mode)
+func (sc *SlabClass) lock() {
+    if sc.concurrent {
+        sc.mutex.Lock()
+    }
+}
+
+// unlock slab class (only in concurrent mode)
+func (sc *SlabClass) unlock() {
+    if sc.concurrent {
+        sc.mutex.Unlock()
+    }
 }
 
 // allocate chunk
 func (sc *SlabClass) chunkAlloc() ([]byte, error) {
+    slab, chunkIndex, err := sc.chunkGet(sc.chunkSize)
+    if err != nil {
+        return nil, err
+    }
+    return slab.chunk(chunkIndex), nil
+}
+
+// allocate chunk for 'size' bytes
+func (sc *SlabClass) chunkGet(size int) (*Slab, int, error) {
+    sc.lock()
+    slab, chunkIndex, err := sc.allocChunk()
+    sc.unlock()
+    if err != nil {
+        return nil, -1, err
+    }
+    sc.chunkGot(slab, chunkIndex, size)
+    return slab, chunkIndex, nil
+}
+
+// allocate 'count' chunks and append them to 'refs'. The chunks are owned
+// by the caller with zero refs, and should be released by chunkReleaseBatch()
+func (sc *SlabClass) chunkAllocBatch(count int, refs []chunkRef) ([]chunkRef, error) {
+    sc.lock()
+    defer sc.unlock()
+
+    for i := 0; i < count; i++ {
+        slab, chunkIndex, err := sc.allocChunk()
+        if err != nil {
+            return refs, err
+        }
+        slab.chunkInfo[chunkIndex].setRef(0)
+        refs = append(refs, chunkRef{slab, chunkIndex})
+    }
+    return refs, nil
+}
+
+// allocate chunk (caller should hold the lock)
+func (sc *SlabClass) allocChunk() (*Slab, int, error) {
+    slab, chunkIndex, err := sc.allocFreeChunk()
+    if err == nil {
+        err = sc.checkAlloc(slab, chunkIndex)
+    }
+    if err != nil {
+        return nil, -1, err
+    }
+    return slab, chunkIndex, nil
+}
+
+// take a free chunk from slabs (caller should hold the lock)
+func (sc *SlabClass) allocFreeChunk() (*Slab, int, error) {
     // 1. try to alloc chunk from slabsUse list
     if !sc.listEmpty(SLAB_USE) {
         head := sc.slabLists[SLAB_USE]
         slab := sc.slabs[head]
-        chunk := slab.chunkAlloc()
+        chunkIndex := slab.chunkAllocIndex()
 
         if slab.status() == SLAB_FULL {
             // remove from slabsUse and add to slabFull
             sc.listRemove(SLAB_USE, head)
             sc.listAdd(SLAB_FULL, head)
         }
-        return chunk, nil
+        return slab, chunkIndex, nil
     }
 
     // 2. try to alloc chunk from slabsFree list
     if !sc.listEmpty(SLAB_FREE) {
         head := sc.slabLists[SLAB_FREE]
         slab := sc.slabs[head]
-        chunk := slab.chunkAlloc()
+        chunkIndex := slab.chunkAllocIndex()
 
         // remove from slabsFree list
         sc.listRemove(SLAB_FREE, head)
@@ -78,37 +302,91 @@ func (sc *SlabClass) chunkAlloc() ([]byte, error) {
             // add to slabsUse list
             sc.listAdd(SLAB_USE, head)
         }
-        return chunk, nil
+        return slab, chunkIndex, nil
     }
 
     // 3. try to alloc new slab
-    slab := sc.slabAlloc()
-    chunk := slab.chunkAlloc()
+    slab, err := sc.slabAlloc()
+    if err != nil {
+        return nil, -1, err
+    }
+    chunkIndex := slab.chunkAllocIndex()
     if slab.status() == SLAB_FULL {
         sc.listAdd(SLAB_FULL, slab.index)
     } else {
         sc.listAdd(SLAB_USE, slab.index)
     }
-    return chunk, nil
+    return slab, chunkIndex, nil
 }
 
 // increase refs for chunk
-func (sc *SlabClass) chunkIncRef(slab *Slab, chunkIndex int) {
-    slab.chunkIncRef(chunkIndex)
+func (sc *SlabClass) chunkIncRef(slab *Slab, chunkIndex int) error {
+    return slab.chunkIncRef(chunkIndex)
 }
 
 // decrease refs for chunk
-func (sc *SlabClass) chunkDecRef(slab *Slab, chunkIndex int) {
-    statusBefore := slab.status()
+func (sc *SlabClass) chunkDecRef(slab *Slab, chunkIndex int) error {
+    // decrease refs for chunk (lock is not needed)
+    released, err := slab.chunkDecRef(chunkIndex)
+    if !released {
+        return err
+    }
+    sc.chunkDropped(slab, chunkIndex)
 
-    // decrease refs for chunk
-    slab.chunkDecRef(chunkIndex)
+    // the last reference is dropped, add chunk to free list
+    sc.lock()
+    defer sc.unlock()
+
+    if sc.chunkHandOff(slab, chunkIndex) {
+        return nil
+    }
+    statusBefore := slab.status()
+    slab.chunkRelease(chunkIndex)
 
     // move slab to new slablist
+    sc.slabMove(slab, statusBefore)
+    return nil
+}
+
+// decrease refs for chunk, return true if refs drops to zero. The chunk
+// is not added to free list of slab, and its owner should release it by
+// chunkReleaseBatch() later
+func (sc *SlabClass) chunkDecRefKeep(slab *Slab, chunkIndex int) (bool, error) {
+    released, err := slab.chunkDecRef(chunkIndex)
+    if released {
+        sc.chunkDropped(slab, chunkIndex)
+    }
+    return released, err
+}
+
+// release chunks (with zero refs) to free list of their slabs
+func (sc *SlabClass) chunkReleaseBatch(refs []chunkRef) {
+    sc.lock()
+    defer sc.unlock()
+
+    for _, ref := range refs {
+        if sc.chunkHandOff(ref.slab, ref.index) {
+            continue
+        }
+        statusBefore := ref.slab.status()
+        ref.slab.chunkRelease(ref.index)
+        sc.slabMove(ref.slab, statusBefore)
+    }
+}
+
+// move slab to new slablist if its status changed
+func (sc *SlabClass) slabMove(slab *Slab, statusBefore int) {
     statusAfter := slab.status()
     if statusBefore != statusAfter {
          sc.listRemove(statusBefore, slab.index)
          sc.listAdd(statusAfter, slab.index)
+         if statusAfter == SLAB_FREE {
+             slab.freeSince = time.Now()
+             // waiters of other slab classes may reclaim memory of the slab
+             if sc.freed != nil {
+                 sc.freed.notify()
+             }
+         }
     }
 }
 
@@ -130,6 +408,8 @@ func (sc *SlabClass) listRemove(whichList int, node int) {
         }
     }
 
+    sc.listLen[whichList]--
+
     // clear node state
     sc.slabs[node].whichList = -1
     sc.slabs[node].prev = -1
@@ -147,6 +427,7 @@ func (sc *SlabClass) listAdd(whichList int, node int) {
     if head >= 0 { // if head is valid node
         sc.slabs[head].prev = node
     }
+    sc.listLen[whichList]++
 }
 
 // check list 'whichlist' is empty or not
@@ -9,7 +9,10 @@ DESCRIPTION
 */
 package slab_pool
 
-import "testing"
+import (
+    "testing"
+    "time"
+)
 
 func TestSlabClassGrowth(t *testing.T) {
     slabClass := NewSlabClass(4096, 2048, 201412)
@@ -126,3 +129,67 @@ func TestSlabListOperation(t *testing.T) {
     }
 }
 
+func TestSlabShrink(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 4096, 4096, 2)
+    slabClass := slabPool.slabClasses[0]
+
+    // five slabs in slabFull list
+    chunks := make([][]byte, 5)
+    for i := range chunks {
+        chunks[i], _ = slabPool.Get(4096)
+    }
+
+    // free slab 0, 2, 3
+    slabPool.Put(chunks[0])
+    slabPool.Put(chunks[2])
+    slabPool.Put(chunks[3])
+
+    // keep one free slab
+    if count := slabClass.slabShrink(1, time.Time{}); count != 2 {
+        t.Errorf("2 slabs should be released, got %d", count)
+    }
+    if len(slabClass.slabs) != 3 {
+        t.Errorf("count for slabs in slabClass should be 3")
+    }
+
+    // check slab index and slab lists
+    countList := [3]int{}
+    for whichList := range slabClass.slabLists {
+        prev := -1
+        for node := slabClass.slabLists[whichList]; node >= 0; node = slabClass.slabs[node].next {
+            slab := slabClass.slabs[node]
+            if slab.index != node || slab.prev != prev || slab.whichList != whichList {
+                t.Errorf("wrong link info for slab %d", node)
+            }
+            prev = node
+            countList[whichList]++
+        }
+    }
+    if countList != [3]int{1, 0, 2} {
+        t.Errorf("wrong count of slabs in slab lists: %v", countList)
+    }
+
+    // stale chunks are rejected
+    if err := slabPool.Put(chunks[0]); err == nil {
+        t.Errorf("should return error for chunk of released slab")
+    }
+
+    // chunks in use are still valid
+    if err := slabPool.Put(chunks[1]); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if err := slabPool.Put(chunks[4]); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if count := slabPool.Shrink(0); count != 3 {
+        t.Errorf("3 slabs should be released, got %d", count)
+    }
+    if len(slabClass.slabs) != 0 || !slabClass.listEmpty(SLAB_FREE) {
+        t.Errorf("all slabs should be released")
+    }
+
+    // allocate after shrink
+    if chunk, err := slabPool.Get(4096); err != nil || len(chunk) != 4096 {
+        t.Errorf("should return valid chunk")
+    }
+}
@@ -0,0 +1,119 @@
+/* slab_debug.go - memory poisoning and canaries in debug mode */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, report slab ID in CorruptionError
+*/
+/*
+DESCRIPTION
+    In debug mode (Options.Debug), each chunk in slab memory is followed by
+    CHUNK_CANARY_LEN canary bytes:
+
+        | chunk 0 | canary | chunk 1 | canary | ... | footer |
+
+    - Chunks are filled with CHUNK_POISON when they are freed (including
+      chunks kept in ChunkCache), and the poison is checked when they are
+      allocated again. Writes after free are reported by Get().
+    - Canaries are checked by Put()/DecRef(), so that writes beyond the end
+      of a chunk (into the next chunk) are reported.
+
+    Corruption is reported by *CorruptionError. A chunk corrupted is
+    quarantined, it is never returned to the pool again.
+*/
+package slab_pool
+
+import (
+    "fmt"
+)
+
+const (
+    CHUNK_CANARY_LEN int  = 8    // canary bytes after each chunk
+    CHUNK_CANARY     byte = 0xa5 // pattern of canary bytes
+    CHUNK_POISON     byte = 0x6b // pattern of free chunks
+)
+
+// CorruptionError reports a corrupted chunk found in debug mode
+type CorruptionError struct {
+    Class  int    // index of slab class
+    Slab   uint64 // slab ID (unique in pool)
+    Chunk  int    // chunk index in slab
+    Offset int    // offset of the first corrupted byte from start of chunk
+    Reason string // "write after free" or "buffer overrun"
+}
+
+func (e *CorruptionError) Error() string {
+    return fmt.Sprintf("chunk corrupted by %s: class %d, slab %d, chunk %d, offset %d",
+                       e.Reason, e.Class, e.Slab, e.Chunk, e.Offset)
+}
+
+func (e *CorruptionError) Unwrap() error {
+    return ErrChunkCorrupted
+}
+
+// initial poison and canaries of all chunks
+func (s *Slab) initDebug() {
+    for i := 0; i < s.countChunk; i++ {
+        s.chunkPoison(i)
+        canary := s.chunkCanary(i)
+        for j := range canary {
+            canary[j] = CHUNK_CANARY
+        }
+    }
+}
+
+// canary bytes of chunk
+func (s *Slab) chunkCanary(index int) []byte {
+    start := s.chunkStride*index + s.chunkSize
+    return s.memory[start : start+CHUNK_CANARY_LEN]
+}
+
+// fill chunk with poison
+func (s *Slab) chunkPoison(index int) {
+    chunk := s.chunk(index)
+    for i := range chunk {
+        chunk[i] = CHUNK_POISON
+    }
+}
+
+// check poison of free chunk
+func (s *Slab) checkPoison(index int) error {
+    for i, b := range s.chunk(index) {
+        if b != CHUNK_POISON {
+            return s.corruption(index, i, "write after free")
+        }
+    }
+    return s.checkCanary(index)
+}
+
+// check canary bytes of chunk
+func (s *Slab) checkCanary(index int) error {
+    for i, b := range s.chunkCanary(index) {
+        if b != CHUNK_CANARY {
+            return s.corruption(index, s.chunkSize+i, "buffer overrun")
+        }
+    }
+    return nil
+}
+
+// create error for corrupted chunk. Slab ID is reported, since slab index
+// may be changed by Shrink() while canaries are checked without lock
+func (s *Slab) corruption(index int, offset int, reason string) error {
+    e := &CorruptionError{Slab: s.id, Chunk: index, Offset: offset, Reason: reason}
+    e.Class = s.slabClass.index
+    return e
+}
+
+// check chunk just allocated, which is quarantined if corrupted
+// (caller should hold the lock)
+func (sc *SlabClass) checkAlloc(slab *Slab, chunkIndex int) error {
+    if !slab.debug {
+        return nil
+    }
+    if err := slab.checkPoison(chunkIndex); err != nil {
+        // chunk with zero refs out of free list is never allocated again
+        slab.chunkInfo[chunkIndex].setRef(0)
+        return err
+    }
+    return nil
+}
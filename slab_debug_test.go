@@ -0,0 +1,125 @@
+/* slab_debug_test.go - unit test for slab_debug.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestDebugLayout(t *testing.T) {
+    options := &Options{Debug: true}
+    if _, err := CreateSlabPoolWithOptions(1024, 1024, 1024, 2, options); err == nil {
+        t.Errorf("slabSize without room for canary should be rejected")
+    }
+
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 1024, 1024, 2, options)
+    chunk, _ := slabPool.Get(1000)
+    slab, chunkIndex, err := slabPool.locate(chunk)
+    if err != nil || chunkIndex != 0 {
+        t.Errorf("chunk should be located: %v", err)
+    }
+    if slab.countChunk != 3 {
+        t.Errorf("count of chunks should be 3, got %d", slab.countChunk)
+    }
+    chunk2, _ := slabPool.Get(1024)
+    if _, chunkIndex, _ = slabPool.locate(chunk2); chunkIndex != 1 {
+        t.Errorf("chunk index should be 1, got %d", chunkIndex)
+    }
+
+    // chunks are poisoned before allocated
+    for i, b := range chunk {
+        if b != CHUNK_POISON {
+            t.Errorf("byte %d of new chunk should be poisoned", i)
+            break
+        }
+    }
+}
+
+func TestDebugWriteAfterFree(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 1024, 1024, 2, &Options{Debug: true})
+    chunk, _ := slabPool.Get(1024)
+    chunk[0] = 1
+    if err := slabPool.Put(chunk); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+
+    // write after free
+    slab, _, _ := slabPool.locate(chunk)
+    chunk[10] = 1
+    _, err := slabPool.Get(1024)
+    var e *CorruptionError
+    if !errors.As(err, &e) || !errors.Is(err, ErrChunkCorrupted) {
+        t.Fatalf("should return CorruptionError, got %v", err)
+    }
+    if e.Class != 0 || e.Slab != slab.id || e.Chunk != 0 || e.Offset != 10 ||
+       e.Reason != "write after free" {
+        t.Errorf("wrong CorruptionError: %+v", e)
+    }
+
+    // chunk corrupted is quarantined
+    chunk2, err := slabPool.Get(1024)
+    if err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if _, chunkIndex, _ := slabPool.locate(chunk2); chunkIndex != 1 {
+        t.Errorf("chunk corrupted should not be allocated again")
+    }
+    if err := slabPool.Put(chunk); err != ErrDoubleFree {
+        t.Errorf("should return ErrDoubleFree, got %v", err)
+    }
+}
+
+func TestDebugBufferOverrun(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 512, 1024, 2, &Options{Debug: true})
+    chunk, _ := slabPool.Get(1000)
+    chunk2, _ := slabPool.Get(1000)
+
+    // write into canary of chunk
+    slab, _, _ := slabPool.locate(chunk)
+    chunk[:cap(chunk)][1026] = 0
+    err := slabPool.Put(chunk)
+    var e *CorruptionError
+    if !errors.As(err, &e) {
+        t.Fatalf("should return CorruptionError, got %v", err)
+    }
+    if e.Class != 1 || e.Slab != slab.id || e.Chunk != 0 || e.Offset != 1026 ||
+       e.Reason != "buffer overrun" {
+        t.Errorf("wrong CorruptionError: %+v", e)
+    }
+
+    // chunk is still allocated
+    if slab.countFree != 1 {
+        t.Errorf("chunk corrupted should not be released")
+    }
+    if err := slabPool.Put(chunk2); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+}
+
+func TestDebugChunkCache(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 1024, 1024, 2, &Options{Debug: true})
+    cache := slabPool.NewChunkCache(&CacheOptions{Depth: 4, Batch: 2})
+    chunk, _ := cache.Get(1024)
+    if err := cache.Put(chunk); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+
+    // write after free into chunk in magazine
+    chunk[1023] = 1
+    _, err := cache.Get(1024)
+    var e *CorruptionError
+    if !errors.As(err, &e) || e.Offset != 1023 {
+        t.Errorf("should return CorruptionError, got %v", err)
+    }
+    if _, err := cache.Get(1024); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+}
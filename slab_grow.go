@@ -0,0 +1,111 @@
+/* slab_grow.go - resize chunks of SlabPool */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, record size of chunk moved
+2026/10/17, by agent, internal resizing path for Buffer
+*/
+/*
+DESCRIPTION
+    Chunks must not be grown by append(). Grow() resizes a chunk instead:
+    - if the slab class of chunk still fits the new size, the same chunk is
+      returned resliced
+    - otherwise the data is moved into a chunk of the slab class fitting the
+      new size (larger or smaller), and the old chunk is released as Put()
+
+    Large chunks (Options.LargeAlloc) and chunks from Go heap are resliced
+    within their own memory, or moved likewise.
+
+Usage:
+    chunk, err := slabPool.Get(100)
+    ...
+    chunk, err = slabPool.Grow(chunk, 1000)
+*/
+package slab_pool
+
+import (
+    "fmt"
+)
+
+/* Grow - resize chunk to 'newSize'
+ *
+ * Params:
+ *     - chunk  : chunk allocated
+ *     - newSize: new size of chunk
+ *
+ * Return:
+ *     - chunk: chunk resized (the same chunk resliced, or a new chunk)
+ *     - err  : error (ErrPoolExhausted if memory limit is hit, ErrInvalidChunk,
+ *              ErrChunkFreed or ErrNotAllocated for wrong chunk)
+ *
+ * Note:
+ *     When the data is moved, the reference of caller on the old chunk is
+ *     released as Put(), and the old chunk must not be used by caller any
+ *     more. Other references on the old chunk stay valid, and the new chunk
+ *     has a single reference. On error, the old chunk is left unchanged.
+ */
+func (sp *SlabPool) Grow(chunk []byte, newSize int) ([]byte, error) {
+    return sp.grow(chunk, newSize, 0)
+}
+
+// resize chunk to 'newSize', 'skip' is the count of frames to skip above
+// caller of grow (0 if called by Grow()) for allocation site of new chunk
+func (sp *SlabPool) grow(chunk []byte, newSize int, skip int) ([]byte, error) {
+    if err := sp.validateChunk(chunk); err != nil {
+        return nil, err
+    }
+    if newSize <= 0 {
+        return nil, fmt.Errorf("illegal chunk size: %d", newSize)
+    }
+
+    // find slab for this chunk
+    slab, chunkIndex, err := sp.locate(chunk)
+    if err != nil {
+        return nil, err
+    }
+    refs := slab.chunkInfo[chunkIndex].getRef()
+    if refs == CHUNK_UNUSED {
+        return nil, ErrNotAllocated
+    }
+    if refs <= 0 {
+        return nil, ErrChunkFreed
+    }
+
+    // reslice chunk if it still fits
+    if sp.chunkFits(slab, newSize) {
+        if slab.slabClass != nil {
+            slab.slabClass.chunkResized(slab, chunkIndex, newSize)
+        }
+        return slab.chunk(chunkIndex)[:newSize], nil
+    }
+
+    // move data into a new chunk
+    if sp.sizes != nil {
+        sp.sizes.record(newSize)
+    }
+    newChunk, err := sp.allocate(newSize)
+    if err != nil {
+        return nil, err
+    }
+    if sp.sites != nil {
+        sp.trackAlloc(newChunk, skip+1)
+    }
+    copy(newChunk, chunk)
+    if err := sp.decRef(chunk, true); err != nil {
+        sp.decRef(newChunk, true)
+        return nil, err
+    }
+    return newChunk, nil
+}
+
+// check whether chunk in slab could be resliced to 'size'
+func (sp *SlabPool) chunkFits(slab *Slab, size int) bool {
+    if slab.slabClass != nil {
+        return sp.slabClassFor(size) == slab.slabClass
+    }
+    if slab.large {
+        return size > sp.chunkSizeMax && size <= slab.chunkSize
+    }
+    return size <= slab.chunkSize
+}
@@ -0,0 +1,151 @@
+/* slab_grow_test.go - unit test for slab_grow.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestGrowInPlace(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 128, 1024, 2)
+    chunk := mustGet(t, slabPool, 100)
+    copy(chunk, "hello")
+
+    grown, err := slabPool.Grow(chunk, 128)
+    if err != nil || len(grown) != 128 || &grown[0] != &chunk[0] {
+        t.Fatalf("chunk should be resliced in place: %v", err)
+    }
+    if string(grown[:5]) != "hello" {
+        t.Errorf("data should be kept")
+    }
+    if c := slabPool.Stats().Classes[0]; c.BytesRequested != 128 || c.Gets != 1 {
+        t.Errorf("wrong stats after resliced: %+v", c)
+    }
+
+    // shrink within the same class
+    grown, err = slabPool.Grow(grown, 65)
+    if err != nil || len(grown) != 65 || &grown[0] != &chunk[0] {
+        t.Errorf("chunk should be resliced in place: %v", err)
+    }
+    if err := slabPool.Put(grown); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if stats := slabPool.Stats(); stats.ChunksInUse != 0 || stats.BytesRequested != 0 {
+        t.Errorf("chunk should be released: %+v", stats)
+    }
+}
+
+func TestGrowMove(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 128, 1024, 2)
+    chunk := mustGet(t, slabPool, 100)
+    copy(chunk, "hello")
+
+    // grow into a larger class
+    grown, err := slabPool.Grow(chunk, 1000)
+    if err != nil || len(grown) != 1000 {
+        t.Fatalf("chunk should be moved: %v", err)
+    }
+    if string(grown[:5]) != "hello" {
+        t.Errorf("data should be copied")
+    }
+    stats := slabPool.Stats()
+    if stats.Classes[0].ChunksInUse != 0 || stats.Classes[3].ChunksInUse != 1 {
+        t.Errorf("chunk should be moved to class of 1024: %+v", stats)
+    }
+    if err := slabPool.Put(chunk); err == nil {
+        t.Errorf("old chunk should be released")
+    }
+
+    // shrink into a smaller class
+    shrunk, err := slabPool.Grow(grown, 10)
+    if err != nil || len(shrunk) != 10 || string(shrunk[:5]) != "hello" {
+        t.Fatalf("chunk should be moved: %v", err)
+    }
+    stats = slabPool.Stats()
+    if stats.Classes[0].ChunksInUse != 1 || stats.Classes[3].ChunksInUse != 0 {
+        t.Errorf("chunk should be moved to class of 128: %+v", stats)
+    }
+    slabPool.Put(shrunk)
+
+    // too large
+    chunk = mustGet(t, slabPool, 100)
+    if _, err := slabPool.Grow(chunk, 2000); err == nil {
+        t.Errorf("should fail to grow chunk beyond chunkSizeMax")
+    }
+    if err := slabPool.Put(chunk); err != nil {
+        t.Errorf("old chunk should be kept on error: %s", err)
+    }
+}
+
+func TestGrowShared(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 128, 1024, 2)
+    chunk := mustGet(t, slabPool, 100)
+    slabPool.IncRef(chunk)
+
+    // reference of caller is released, the other one is kept
+    grown, err := slabPool.Grow(chunk, 500)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if err := slabPool.DecRef(chunk); err != nil {
+        t.Errorf("other reference should be kept: %s", err)
+    }
+    if err := slabPool.DecRef(chunk); !errors.Is(err, ErrDoubleFree) {
+        t.Errorf("expected ErrDoubleFree, got %v", err)
+    }
+    if err := slabPool.Put(grown); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+
+    // chunk released
+    if _, err := slabPool.Grow(chunk, 200); !errors.Is(err, ErrChunkFreed) {
+        t.Errorf("expected ErrChunkFreed, got %v", err)
+    }
+}
+
+func TestGrowLarge(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2, &Options{LargeAlloc: true})
+    chunk := mustGet(t, slabPool, 1000)
+    copy(chunk, "hello")
+
+    large, err := slabPool.Grow(chunk, 10000)
+    if err != nil || len(large) != 10000 || string(large[:5]) != "hello" {
+        t.Fatalf("chunk should be moved to large chunk: %v", err)
+    }
+    resliced, err := slabPool.Grow(large, 5000)
+    if err != nil || &resliced[0] != &large[0] {
+        t.Errorf("large chunk should be resliced in place: %v", err)
+    }
+    small, err := slabPool.Grow(resliced, 100)
+    if err != nil || len(small) != 100 || string(small[:5]) != "hello" {
+        t.Errorf("large chunk should be moved to slab class: %v", err)
+    }
+    if stats := slabPool.Stats(); stats.LargeChunks != 0 || stats.ChunksInUse != 1 {
+        t.Errorf("wrong stats: %+v", stats)
+    }
+    slabPool.Put(small)
+}
+
+func TestGrowRecordSizes(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 128, 1024, 2,
+                                            &Options{RecordSizes: true})
+    chunk := mustGet(t, slabPool, 10)
+    chunk, err := slabPool.Grow(chunk, 1000)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    slabPool.Put(chunk)
+
+    buckets := slabPool.SizeHistogram()
+    if len(buckets) != 2 || buckets[1] != (SizeBucket{Min: 992, Max: 1023, Count: 1}) {
+        t.Errorf("size of chunk moved should be recorded: %v", buckets)
+    }
+}
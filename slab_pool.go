@@ -3,6 +3,30 @@
 modification history
 --------------------
 2014/12/2, by Sijie Yang, create
+2026/10/17, by agent, add CreateSlabPoolWithOptions() and concurrent mode
+2026/10/17, by agent, add Shrink() and Close()
+2026/10/17, by agent, add memory limits and GetContext()
+2026/10/17, by agent, wake up waiters of GetContext() in FIFO order
+2026/10/17, by agent, add arena mode
+2026/10/17, by agent, locate slab of chunk by slabTable
+2026/10/17, by agent, return typed errors for wrong chunks
+2026/10/17, by agent, add debug mode
+2026/10/17, by agent, track allocation sites
+2026/10/17, by agent, add Stats()
+2026/10/17, by agent, record sizes requested
+2026/10/17, by agent, add CreateSlabPoolWithClasses()
+2026/10/17, by agent, support automatic slab size of each slab class
+2026/10/17, by agent, always generate a slab class for chunkSizeMax
+2026/10/17, by agent, serve sizes above chunkSizeMax with LargeAlloc
+2026/10/17, by agent, reclaim free slabs of other classes when MaxBytes is hit
+2026/10/17, by agent, remove waiter of GetContext() on allocation error
+2026/10/17, by agent, reject chunks not in arena quickly in arena mode
+2026/10/17, by agent, reject HugePages/Populate without Mmap
+2026/10/17, by agent, follow exhaust policy and own budget for large chunks
+2026/10/17, by agent, internal allocation path for Buffer
+2026/10/17, by agent, require the same slab size in arena mode
+2026/10/17, by agent, reject chunks with capacity beyond slab
+2026/10/17, by agent, release free slabs and arena by Close()
 */
 /*
 DESCRIPTION
@@ -11,6 +35,14 @@ Usage:
     // Create slab pool
     slabPool, err := CreateSlabPool(4096, 128, 1024, 2)
 
+    // Create slab pool shared by multiple goroutines
+    slabPool, err := CreateSlabPoolWithOptions(4096, 128, 1024, 2,
+                                               &Options{Concurrent: true})
+
+    // Create slab pool with explicit chunk sizes
+    slabPool, err := CreateSlabPoolWithClasses(4096, []ClassConfig{
+            {ChunkSize: 72}, {ChunkSize: 200}, {ChunkSize: 1500, SlabSize: 15000}}, nil)
+
     // Allocate chunk
     chunk, err := slabPool.Get(500)
 
@@ -22,28 +54,52 @@ Usage:
     slabPool.IncRef(chunk2)
     slabPool.DecRef(chunk2)
 
+    // Release free slabs
+    slabPool.Shrink(0)
+
 Note:
-    Must Not append() on chunk allocated.
+    Must Not append() on chunk allocated, use Grow() instead.
 */
 package slab_pool
 
 import (
-    "encoding/binary"
+    "context"
+    "errors"
     "fmt"
     "math/rand"
     "sort"
-    "unsafe"
+    "sync"
+    "sync/atomic"
+    "time"
 )
 
 type SlabPool struct {
-    slabClasses  []SlabClass // SlabClasses with different chunk size
-
-    slabSize     int         // slab size (bytes)
-    chunkSizeMax int         // max chunk size (bytes)
-    chunkSizeMin int         // min chunk size (bytes)
-    factor       float64     // growth factor for chunk size
-
-    slabMagic    uint64      // magic number for slab
+    heapAllocs   uint64       // chunks from Go heap (first for alignment of atomics)
+    largeAllocs  uint64       // large chunks allocated (LargeAlloc only)
+    largeChunks  int64        // large chunks in use
+    largeBytes   int64        // memory of large chunks in use (including footers)
+
+    slabClasses  []*SlabClass // SlabClasses with different chunk size
+
+    slabSize     int          // slab size (bytes)
+    chunkSizeMax int          // max chunk size (bytes)
+    chunkSizeMin int          // min chunk size (bytes)
+    factor       float64      // growth factor for chunk size (0 for explicit classes)
+
+    slabMagic    uint64       // magic number for slab
+    options      Options      // options for slab pool
+
+    table        *slabTable   // index of slabs
+    limit        *memLimit    // memory limit for slabs
+    largeLimit   *memLimit    // memory limit for large chunks (limit, or unlimited
+                              // in arena mode)
+    arena        *ArenaProvider // arena for slabs (arena mode only)
+    freed        *notifier    // notified when slab memory is freed
+    sites        *siteTable   // allocation sites (TrackAllocations only)
+    sizes        *sizeHistogram // sizes requested (RecordSizes only)
+
+    closeOnce    sync.Once
+    closeChan    chan struct{} // closed when slab pool is closed
 }
 
 /* CreateSlabPool - create slab pool
@@ -60,21 +116,151 @@ type SlabPool struct {
  */
 func CreateSlabPool(slabSize int, chunkSizeMin int, chunkSizeMax int, factor float64) (
     *SlabPool, error) {
+    return CreateSlabPoolWithOptions(slabSize, chunkSizeMin, chunkSizeMax, factor, nil)
+}
+
+/* CreateSlabPoolWithOptions - create slab pool with options
+ *
+ * Params:
+ *     - slabSize    : size of slab (bytes)
+ *     - chunkSizeMin: min chunk size (bytes)
+ *     - chunkSizeMax: max chunk size (bytes)
+ *     - factor      : growth factor for chunk size
+ *     - options     : options for slab pool (nil for default options)
+ *
+ * Return:
+ *     - slabPool    : slab pool
+ *     - error       : nil if success, error if failure
+ */
+func CreateSlabPoolWithOptions(slabSize int, chunkSizeMin int, chunkSizeMax int,
+    factor float64, options *Options) (*SlabPool, error) {
     if err := validateParams(slabSize, chunkSizeMin, chunkSizeMax, factor); err != nil {
         return nil, fmt.Errorf("wrong params: %s", err)
     }
 
+    // chunk sizes generated by factor
+    classes := make([]ClassConfig, 0)
+    for chunkSize := chunkSizeMin; chunkSize <= chunkSizeMax; {
+        classes = append(classes, ClassConfig{ChunkSize: chunkSize})
+        chunkSize = int((float64(chunkSize) * factor))
+    }
+    // chunkSizeMax may be skipped by factor, and it should be served too
+    if classes[len(classes)-1].ChunkSize < chunkSizeMax {
+        classes = append(classes, ClassConfig{ChunkSize: chunkSizeMax})
+    }
+
+    sp, err := createSlabPool(slabSize, classes, options)
+    if err != nil {
+        return nil, err
+    }
+    sp.factor = factor
+    return sp, nil
+}
+
+/* CreateSlabPoolWithClasses - create slab pool with explicit slab classes
+ *
+ * Params:
+ *     - slabSize: default size of slab (bytes)
+ *     - classes : slab classes, in ascending order of chunk size
+ *     - options : options for slab pool (nil for default options)
+ *
+ * Return:
+ *     - slabPool: slab pool
+ *     - error   : nil if success, error if failure
+ */
+func CreateSlabPoolWithClasses(slabSize int, classes []ClassConfig, options *Options) (
+    *SlabPool, error) {
+    if err := validateClasses(slabSize, classes); err != nil {
+        return nil, fmt.Errorf("wrong params: %s", err)
+    }
+
+    return createSlabPool(slabSize, classes, options)
+}
+
+// create slab pool with slab classes
+func createSlabPool(slabSize int, classes []ClassConfig, options *Options) (*SlabPool, error) {
     sp := new(SlabPool)
     sp.slabSize = slabSize
-    sp.chunkSizeMax = chunkSizeMax
-    sp.chunkSizeMin = chunkSizeMin
-    sp.factor = factor
+    sp.chunkSizeMin = classes[0].ChunkSize
+    sp.chunkSizeMax = classes[len(classes)-1].ChunkSize
     sp.slabMagic = uint64(rand.Int63())
-    sp.initSlabClass()
+    if options != nil {
+        sp.options = *options
+    }
+    if err := validateOptions(&sp.options); err != nil {
+        return nil, fmt.Errorf("wrong options: %s", err)
+    }
+    classes = sp.resolveSlabSizes(classes)
+    if sp.options.Debug {
+        for _, class := range classes {
+            if class.SlabSize < class.ChunkSize+CHUNK_CANARY_LEN {
+                return nil, fmt.Errorf("wrong options: slab size should be no less than "+
+                                       "%d in debug mode", class.ChunkSize+CHUNK_CANARY_LEN)
+            }
+        }
+    }
+    if sp.options.ArenaSize > 0 {
+        if err := sp.initArena(classes); err != nil {
+            return nil, fmt.Errorf("init arena: %s", err)
+        }
+    }
+    if sp.options.MemoryProvider == nil && sp.options.Mmap {
+        sp.options.MemoryProvider = sp.mmapProvider()
+    }
+    if sp.options.ShrinkPolicy != nil || sp.options.ExhaustPolicy == EXHAUST_BLOCK {
+        sp.options.Concurrent = true
+    }
+    sp.table = newSlabTable()
+    sp.limit = &memLimit{maxBytes: sp.options.MaxBytes}
+    sp.largeLimit = sp.limit
+    if sp.arena != nil {
+        // MaxBytes is the budget of arena, which large chunks are not in
+        sp.largeLimit = new(memLimit)
+    }
+    if sp.options.ExhaustPolicy == EXHAUST_BLOCK {
+        sp.freed = newNotifier()
+    }
+    if sp.options.TrackAllocations {
+        sp.sites = new(siteTable)
+    }
+    if sp.options.RecordSizes {
+        sp.sizes = new(sizeHistogram)
+    }
+    sp.closeChan = make(chan struct{})
+    sp.initSlabClass(classes)
+
+    // start background shrinking
+    if sp.options.ShrinkPolicy != nil {
+        go sp.shrinkLoop(*sp.options.ShrinkPolicy)
+    }
 
     return sp, nil
 }
 
+/* Close - stop background goroutines and release memory of slab pool
+ *
+ * Note:
+ *     Free slabs are released, so memory from mmap is unmapped. The arena
+ *     is released too if no chunk in it is in use, and Get() returns
+ *     ErrPoolExhausted after that.
+ *     Chunks allocated are still valid after Close(), but memory of their
+ *     slabs is kept until they are put back and Shrink() is called. So all
+ *     chunks should be put back before Close() to release all memory.
+ */
+func (sp *SlabPool) Close() {
+    sp.closeOnce.Do(func() {
+        close(sp.closeChan)
+
+        // memory from mmap is not reclaimed by GC
+        sp.Shrink(0)
+        if sp.arena != nil {
+            if memory := sp.arena.release(); memory != nil && sp.options.Mmap {
+                sp.mmapProvider().Free(memory)
+            }
+        }
+    })
+}
+
 // validate parameters for init slabpool
 func validateParams(slabSize int, chunkSizeMin int, chunkSizeMax int, factor float64) error {
     if chunkSizeMin <= 0 || chunkSizeMin > chunkSizeMax {
@@ -92,16 +278,128 @@ func validateParams(slabSize int, chunkSizeMin int, chunkSizeMax int, factor flo
     return nil
 }
 
-// initial slabclasses
-func (sp *SlabPool) initSlabClass() {
-    sp.slabClasses = make([]SlabClass, 0)
+// validate slab classes for init slabpool
+func validateClasses(slabSize int, classes []ClassConfig) error {
+    if slabSize <= 0 {
+        return fmt.Errorf("slabSize should be greater than 0")
+    }
+    if len(classes) == 0 {
+        return fmt.Errorf("no slab class")
+    }
+    for i, class := range classes {
+        if class.ChunkSize <= 0 {
+            return fmt.Errorf("chunk size should be greater than 0")
+        }
+        if i > 0 && class.ChunkSize <= classes[i-1].ChunkSize {
+            return fmt.Errorf("chunk sizes should be in ascending order")
+        }
+        if class.SlabSize < 0 {
+            return fmt.Errorf("slab size should be no less than 0")
+        }
+        if class.SlabSize == 0 && slabSize < class.ChunkSize ||
+           class.SlabSize > 0 && class.SlabSize < class.ChunkSize {
+            return fmt.Errorf("slab size should be no less than chunk size %d",
+                              class.ChunkSize)
+        }
+    }
+    return nil
+}
 
-    chunkSize := sp.chunkSizeMin
-    for chunkSize <= sp.chunkSizeMax {
-        slabClass := NewSlabClass(sp.slabSize, chunkSize, sp.slabMagic)
-        sp.slabClasses = append(sp.slabClasses, *slabClass)
+// validate options for init slabpool
+func validateOptions(options *Options) error {
+    if options.MaxBytes < 0 {
+        return fmt.Errorf("MaxBytes should be no less than 0")
+    }
+    if options.MaxSlabsPerClass < 0 {
+        return fmt.Errorf("MaxSlabsPerClass should be no less than 0")
+    }
+    if options.SlabWaste < 0 || options.SlabWaste >= 1 {
+        return fmt.Errorf("SlabWaste should be in [0, 1)")
+    }
+    if options.ArenaSize < 0 {
+        return fmt.Errorf("ArenaSize should be no less than 0")
+    }
+    if options.ArenaSize > 0 && options.MemoryProvider != nil {
+        return fmt.Errorf("ArenaSize can not be used with MemoryProvider")
+    }
+    if (options.HugePages || options.Populate) &&
+       (!options.Mmap || options.MemoryProvider != nil) {
+        return fmt.Errorf("HugePages and Populate need Mmap without MemoryProvider")
+    }
+    if options.HugePages && options.ArenaSize%HUGE_PAGE_SIZE != 0 {
+        return fmt.Errorf("ArenaSize should be a multiple of %d with HugePages",
+                          HUGE_PAGE_SIZE)
+    }
+    switch options.ExhaustPolicy {
+    case EXHAUST_ERROR, EXHAUST_BLOCK, EXHAUST_HEAP:
+    default:
+        return fmt.Errorf("unknown ExhaustPolicy %d", options.ExhaustPolicy)
+    }
+    return nil
+}
 
-        chunkSize = int((float64(chunkSize) * sp.factor))
+// initial arena for slabs
+func (sp *SlabPool) initArena(classes []ClassConfig) error {
+    slabMemSize := 0
+    for _, class := range classes {
+        if class.SlabSize+SLAB_FOOTER_LEN > slabMemSize {
+            slabMemSize = class.SlabSize + SLAB_FOOTER_LEN
+        }
+    }
+    // memory of slabs freed is reused by slabs of any slab class, so all
+    // slabs in arena have the same size
+    for _, class := range classes {
+        if class.SlabSize+SLAB_FOOTER_LEN != slabMemSize {
+            return fmt.Errorf("arena mode needs the same slab size for all slab classes")
+        }
+    }
+    if sp.options.ArenaSize < slabMemSize {
+        return fmt.Errorf("ArenaSize should be no less than %d", slabMemSize)
+    }
+
+    // reserve memory for arena
+    var memory []byte
+    if sp.options.Mmap {
+        var err error
+        if memory, err = sp.mmapProvider().Alloc(sp.options.ArenaSize); err != nil {
+            return err
+        }
+    } else {
+        memory = make([]byte, sp.options.ArenaSize)
+    }
+    sp.arena = newArenaProvider(memory)
+    if sp.options.ArenaEager {
+        sp.arena.carve(slabMemSize)
+    }
+
+    sp.options.MemoryProvider = sp.arena
+    arenaSize := int64(sp.options.ArenaSize)
+    if sp.options.MaxBytes == 0 || sp.options.MaxBytes > arenaSize {
+        sp.options.MaxBytes = arenaSize
+    }
+    return nil
+}
+
+// mmap provider for slab memory
+func (sp *SlabPool) mmapProvider() MmapProvider {
+    return MmapProvider{HugePages: sp.options.HugePages, Populate: sp.options.Populate}
+}
+
+// initial slabclasses
+func (sp *SlabPool) initSlabClass(classes []ClassConfig) {
+    sp.slabClasses = make([]*SlabClass, 0, len(classes))
+
+    for _, class := range classes {
+        slabClass := NewSlabClass(class.SlabSize, class.ChunkSize, sp.slabMagic)
+        slabClass.index = len(sp.slabClasses)
+        slabClass.debug = sp.options.Debug
+        slabClass.concurrent = sp.options.Concurrent
+        slabClass.provider = sp.options.MemoryProvider
+        slabClass.maxSlabs = sp.options.MaxSlabsPerClass
+        slabClass.table = sp.table
+        slabClass.limit = sp.limit
+        slabClass.freed = sp.freed
+        sp.slabClasses = append(sp.slabClasses, slabClass)
     }
 }
 
@@ -112,25 +410,163 @@ func (sp *SlabPool) initSlabClass() {
  *
  * Return:
  *     - chunk: chunk allocated
- *     - err  : error
+ *     - err  : error (ErrPoolExhausted if memory limit is hit)
  *
  * Note:
  *     Must Not apppend() on return chunk
+ *     Size above chunkSizeMax is served only with Options.LargeAlloc
  */
 func (sp *SlabPool) Get(size int) ([]byte, error) {
+    return sp.getTracked(size, 0)
+}
+
+// allocate a chunk recording its size and allocation site, 'skip' is the
+// count of frames to skip above caller of getTracked (0 if called by Get())
+func (sp *SlabPool) getTracked(size int, skip int) ([]byte, error) {
+    if sp.sizes != nil {
+        sp.sizes.record(size)
+    }
+    chunk, err := sp.allocate(size)
+    if err == nil && sp.sites != nil {
+        sp.trackAlloc(chunk, skip+1)
+    }
+    return chunk, err
+}
+
+// allocate a chunk with length 'size', following the exhaust policy
+func (sp *SlabPool) allocate(size int) ([]byte, error) {
+    if sp.options.ExhaustPolicy == EXHAUST_BLOCK {
+        return sp.getContext(context.Background(), size)
+    }
+    return sp.get(size)
+}
+
+/* GetContext - allocate a chunk with length 'size'
+ *
+ * Params:
+ *     - ctx : context for waiting
+ *     - size: chunk size
+ *
+ * Return:
+ *     - chunk: chunk allocated
+ *     - err  : error (ctx.Err() if ctx is done before allocated)
+ *
+ * Note:
+ *     With EXHAUST_BLOCK policy, GetContext() parks the caller until a
+ *     chunk of the same slab class is released, or ctx is done. Waiters
+ *     of each slab class are woken in FIFO order. Otherwise, it is the
+ *     same as Get().
+ */
+func (sp *SlabPool) GetContext(ctx context.Context, size int) ([]byte, error) {
+    if sp.sizes != nil {
+        sp.sizes.record(size)
+    }
+    chunk, err := sp.getContext(ctx, size)
+    if err == nil && sp.sites != nil {
+        sp.trackAlloc(chunk, 0)
+    }
+    return chunk, err
+}
+
+// allocate a chunk with length 'size' (blocking with EXHAUST_BLOCK policy)
+func (sp *SlabPool) getContext(ctx context.Context, size int) ([]byte, error) {
+    if sp.options.ExhaustPolicy != EXHAUST_BLOCK {
+        return sp.get(size)
+    }
+
+    chunk, err := sp.get(size)
+    if !errors.Is(err, ErrPoolExhausted) {
+        return chunk, err
+    }
+
+    // large chunks are not waited for
+    slabClass := sp.slabClassFor(size)
+    if slabClass == nil {
+        return chunk, err
+    }
+
+    // wait until a chunk is released, or slab memory is freed
+    waiter := newChunkWaiter()
+    for {
+        memFreed := sp.freed.enter()
+        slab, chunkIndex, err := slabClass.chunkAllocOrWait(waiter)
+        if !errors.Is(err, ErrPoolExhausted) {
+            sp.freed.leave()
+            if err != nil {
+                // chunk released later should not be handed to the waiter
+                slabClass.waiterAbandon(waiter)
+                return nil, fmt.Errorf("Get(): %w", err)
+            }
+            slabClass.chunkGot(slab, chunkIndex, size)
+            return slab.chunk(chunkIndex)[:size], nil
+        }
+        if sp.reclaim(slabClass, slabClass.slabMemSize()) {
+            // try again with memory of free slabs reclaimed
+            sp.freed.leave()
+            continue
+        }
+
+        select {
+        case ref := <-waiter.ch:
+            sp.freed.leave()
+            slabClass.chunkGot(ref.slab, ref.index, size)
+            return ref.slab.chunk(ref.index)[:size], nil
+        case <-memFreed:
+            sp.freed.leave()
+        case <-ctx.Done():
+            sp.freed.leave()
+            if !slabClass.waiterCancel(waiter) {
+                // a chunk has been handed to waiter
+                ref := <-waiter.ch
+                slabClass.chunkGot(ref.slab, ref.index, size)
+                return ref.slab.chunk(ref.index)[:size], nil
+            }
+            return nil, ctx.Err()
+        }
+    }
+}
+
+// allocate a chunk with length 'size' (no blocking)
+func (sp *SlabPool) get(size int) ([]byte, error) {
+    if size > sp.chunkSizeMax && sp.options.LargeAlloc {
+        chunk, err := sp.largeAlloc(size)
+        if errors.Is(err, ErrPoolExhausted) && sp.options.ExhaustPolicy == EXHAUST_HEAP {
+            return sp.heapAlloc(size), nil
+        }
+        return chunk, err
+    }
     if size > sp.chunkSizeMax || size <= 0 {
-        return nil, fmt.Errorf("illegal chunk size: %s", size)
+        return nil, fmt.Errorf("illegal chunk size: %d", size)
     }
 
     // find slab class by chunk size
     slabClass := sp.slabClassFor(size)
+    if slabClass == nil {
+        return nil, fmt.Errorf("no slab class for chunk size: %d", size)
+    }
 
     // get free chunk from slab class
-    chunk, err := slabClass.chunkAlloc()
+    slab, chunkIndex, err := slabClass.chunkGet(size)
+    if errors.Is(err, ErrPoolExhausted) && sp.reclaim(slabClass, slabClass.slabMemSize()) {
+        // try again with memory of free slabs reclaimed
+        slab, chunkIndex, err = slabClass.chunkGet(size)
+    }
+    if errors.Is(err, ErrPoolExhausted) && sp.options.ExhaustPolicy == EXHAUST_HEAP {
+        return sp.heapAlloc(size), nil
+    }
     if err != nil {
-        return nil, fmt.Errorf("Get(): %s", err.Error())
+        return nil, fmt.Errorf("Get(): %w", err)
     }
-    return chunk[:size], nil
+    return slab.chunk(chunkIndex)[:size], nil
+}
+
+// allocate chunk from Go heap, by a single-chunk slab without slab class.
+// The slab is removed from slabTable when its chunk is released.
+func (sp *SlabPool) heapAlloc(size int) []byte {
+    slab := NewSlab(nil, size, size, sp.slabMagic)
+    sp.table.register(slab)
+    atomic.AddUint64(&sp.heapAllocs, 1)
+    return slab.chunkAlloc()
 }
 
 /* Put - release chunk to slab pool
@@ -139,10 +575,11 @@ func (sp *SlabPool) Get(size int) ([]byte, error) {
  *     - chunk: chunk to release
  *
  * Return:
- *     - err: error
+ *     - err: error (ErrInvalidChunk, ErrDoubleFree or ErrNotAllocated for
+ *            wrong chunk)
  */
 func (sp *SlabPool) Put(chunk []byte) error {
-    return sp.DecRef(chunk)
+    return sp.decRef(chunk, true)
 }
 
 /* IncRef - increase reference for chunk
@@ -151,7 +588,8 @@ func (sp *SlabPool) Put(chunk []byte) error {
  *     chunk: chunk allocated
  *
  * Return:
- *     err: error
+ *     err: error (ErrInvalidChunk, ErrChunkFreed or ErrNotAllocated for
+ *          wrong chunk)
  */
 func (sp *SlabPool) IncRef(chunk []byte) error {
     if err := sp.validateChunk(chunk); err != nil {
@@ -164,9 +602,17 @@ func (sp *SlabPool) IncRef(chunk []byte) error {
         return err
     }
 
+    // chunk from Go heap
+    if slab.slabClass == nil {
+        return slab.chunkIncRef(chunkIndex)
+    }
+
     // increase reference count for chunk
     slabClass := slab.slabClass
-    slabClass.chunkIncRef(slab, chunkIndex)
+    if err := slabClass.chunkIncRef(slab, chunkIndex); err != nil {
+        return err
+    }
+    atomic.AddUint64(&slabClass.counters.incRefs, 1)
     return nil
 }
 
@@ -176,9 +622,15 @@ func (sp *SlabPool) IncRef(chunk []byte) error {
  *     chunk: chunk allocated
  *
  * Return:
- *     err: error
+ *     err: error (ErrInvalidChunk, ErrDoubleFree or ErrNotAllocated for
+ *          wrong chunk)
  */
 func (sp *SlabPool) DecRef(chunk []byte) error {
+    return sp.decRef(chunk, false)
+}
+
+// decrease reference for chunk, 'put' is true for Put()
+func (sp *SlabPool) decRef(chunk []byte, put bool) error {
     if err := sp.validateChunk(chunk); err != nil {
         return err
     }
@@ -189,61 +641,109 @@ func (sp *SlabPool) DecRef(chunk []byte) error {
         return err
     }
 
+    // chunk from Go heap is reclaimed by GC after released, and
+    // memory of large chunk is freed after released
+    if slab.slabClass == nil {
+        released, err := slab.chunkDecRef(chunkIndex)
+        if released {
+            sp.table.unregister(slab)
+            if slab.large {
+                sp.largeRelease(slab)
+            }
+        }
+        return err
+    }
+
     // decrease reference count for chunk
     slabClass := slab.slabClass
-    slabClass.chunkDecRef(slab, chunkIndex)
+    if err := slabClass.chunkDecRef(slab, chunkIndex); err != nil {
+        return err
+    }
+    slabClass.countDecRef(put)
     return nil
 }
 
+/* Shrink - release free slabs
+ *
+ * Params:
+ *     - keepFree: count of free slabs kept in each slab class
+ *
+ * Return:
+ *     - count of slabs released
+ *
+ * Note:
+ *     Chunks released to the pool must not be used any more. Put()
+ *     on stale chunks of released slabs returns error.
+ */
+func (sp *SlabPool) Shrink(keepFree int) int {
+    return sp.shrinkIdle(keepFree, time.Time{})
+}
+
 // validate input chunk
 func (sp *SlabPool) validateChunk(chunk []byte) error {
     // check chunk not nil
     if chunk == nil {
-        return fmt.Errorf("chunk is nil")
+        return fmt.Errorf("chunk is nil: %w", ErrInvalidChunk)
     }
     // check chunk size
-    if len(chunk) <= 0 || len(chunk) > sp.chunkSizeMax {
-        return fmt.Errorf("chunk size should be no greater than %s", sp.chunkSizeMax)
+    if len(chunk) <= 0 || (len(chunk) > sp.chunkSizeMax && !sp.options.LargeAlloc) {
+        return fmt.Errorf("chunk size should be no greater than %d: %w",
+                          sp.chunkSizeMax, ErrInvalidChunk)
     }
     // check chunk capacity (must not be changed)
     if cap(chunk) <= SLAB_FOOTER_LEN {
-        return fmt.Errorf("chunk capicity should not be changed")
+        return fmt.Errorf("chunk capicity should not be changed: %w", ErrInvalidChunk)
     }
     return nil
 }
 
-// find slabClass with matched chunksize
+// find slabClass with matched chunksize (nil if no slabClass is large enough)
 func (sp *SlabPool) slabClassFor(size int) *SlabClass {
-    i := sort.Search(len(sp.slabClasses),
+    i := sp.classIndexFor(size)
+    if i >= len(sp.slabClasses) {
+        return nil
+    }
+    return sp.slabClasses[i]
+}
+
+// find index of slabClass with matched chunksize
+func (sp *SlabPool) classIndexFor(size int) int {
+    return sort.Search(len(sp.slabClasses),
         func(i int) bool {
             return size <= sp.slabClasses[i].chunkSize
         })
-    return &(sp.slabClasses[i])
 }
 
 // find slab and chunkIndex for input chunk
 func (sp *SlabPool) locate(chunk []byte) (*Slab, int, error) {
-    // locate footer area
-    restArea := chunk[:cap(chunk)]
-    footer := restArea[len(restArea)-SLAB_FOOTER_LEN:]
+    // all chunks are in arena, unless chunks from Go heap or large chunks
+    // may be allocated
+    if sp.arena != nil && sp.options.ExhaustPolicy != EXHAUST_HEAP &&
+       !sp.options.LargeAlloc && !sp.arena.contains(chunk) {
+        return nil, -1, fmt.Errorf("chunk not in arena of this pool: %w", ErrInvalidChunk)
+    }
 
-    // read slab pointer
-    slab, err := sp.getSlabPtr(footer)
-    if err != nil {
-        return nil, -1, err
+    // find slab by end of chunk capacity
+    slab := sp.table.lookup(chunk)
+    if slab == nil {
+        return nil, -1, fmt.Errorf("slab not found, a chunk not allocted from this pool? %w",
+                                   ErrInvalidChunk)
     }
 
-    // return slab and chunk index
-    chunkIndex := (slab.slabSize + SLAB_FOOTER_LEN - cap(chunk)) / slab.chunkSize
-    return slab, chunkIndex, nil
-}
+    // check chunk is at boundary of chunks in slab
+    offset := slab.slabSize + SLAB_FOOTER_LEN - cap(chunk)
+    if offset < 0 || offset%slab.chunkStride != 0 ||
+       offset/slab.chunkStride >= slab.countChunk {
+        return nil, -1, fmt.Errorf("chunk not at boundary of chunks in slab: %w",
+                                   ErrInvalidChunk)
+    }
 
-// get slab pointer from footer area
-func (sp *SlabPool) getSlabPtr(footer []byte) (*Slab, error) {
-    slabMagic := binary.BigEndian.Uint64(footer[0:8])
-    slabPtr := binary.BigEndian.Uint64(footer[8:16])
-    if slabMagic != sp.slabMagic { // validate slabPtr by slabMagic
-        return nil, fmt.Errorf("magic number not matched, a chunk not allocted from this pool?")
+    // check footer of slab
+    if !slab.checkFooter() {
+        return nil, -1, fmt.Errorf("footer of slab corrupted, slab %d: %w",
+                                   slab.id, ErrInvalidChunk)
     }
-    return (*Slab)(unsafe.Pointer(uintptr(slabPtr))), nil
+
+    // return slab and chunk index
+    return slab, offset / slab.chunkStride, nil
 }
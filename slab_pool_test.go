@@ -9,7 +9,11 @@ DESCRIPTION
 */
 package slab_pool
 
-import "testing"
+import (
+    "errors"
+    "sync"
+    "testing"
+)
 
 func TestCreateSlabPool(t *testing.T) {
     var err error
@@ -46,7 +50,7 @@ func TestSlabClassFor(t *testing.T) {
     test := func (bufSize int, chunkSize int) {
         slabClass := slabPool.slabClassFor(bufSize)
         if slabClass.chunkSize != chunkSize {
-            t.Errorf("expected slabClass with chunkSize:%s, got:%s", 
+            t.Errorf("expected slabClass with chunkSize:%d, got:%d", 
                      chunkSize, slabClass.chunkSize)
         }
     }
@@ -61,6 +65,52 @@ func TestSlabClassFor(t *testing.T) {
     test(512, 512)
     test(1023, 1024)
     test(1024, 1024)
+
+    // no slabClass for size larger than all chunk sizes
+    if slabPool.slabClassFor(1025) != nil {
+        t.Errorf("expected no slabClass for size larger than chunkSizeMax")
+    }
+}
+
+func TestChunkSizeMaxClass(t *testing.T) {
+    // factor skips chunkSizeMax: 100, 200, 400, 800, then 1000
+    slabPool, _ := CreateSlabPool(4096, 100, 1000, 2)
+    sizes := []int{100, 200, 400, 800, 1000}
+    if len(slabPool.slabClasses) != len(sizes) {
+        t.Fatalf("expected %d slab classes, got %d", len(sizes), len(slabPool.slabClasses))
+    }
+    for i, size := range sizes {
+        if slabPool.slabClasses[i].chunkSize != size {
+            t.Errorf("chunk size of class %d should be %d", i, size)
+        }
+    }
+
+    for _, size := range []int{801, 900, 1000} {
+        chunk, err := slabPool.Get(size)
+        if err != nil || len(chunk) != size {
+            t.Errorf("should allocate chunk of size %d: %v", size, err)
+        }
+        if err := slabPool.Put(chunk); err != nil {
+            t.Errorf("unexpected error: %s", err)
+        }
+    }
+    if _, err := slabPool.Get(1001); err == nil {
+        t.Errorf("should fail to allocate chunk larger than chunkSizeMax")
+    }
+
+    // chunk cache
+    cache := slabPool.NewChunkCache(nil)
+    chunk, err := cache.Get(900)
+    if err != nil || len(chunk) != 900 {
+        t.Errorf("should allocate chunk of size 900 from cache: %v", err)
+    }
+    cache.Put(chunk)
+
+    // chunkSizeMax reached by factor exactly
+    slabPool, _ = CreateSlabPool(4096, 128, 1024, 2)
+    if len(slabPool.slabClasses) != 4 {
+        t.Errorf("expected 4 slab classes, got %d", len(slabPool.slabClasses))
+    }
 }
 
 func TestGet(t *testing.T) {
@@ -202,6 +252,72 @@ func TestIncAndDecRef_2(t *testing.T) {
     }
 }
 
+func TestConcurrentGetAndPut(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 64, 1024, 2, &Options{Concurrent: true})
+
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        wg.Add(1)
+        go func(id int) {
+            defer wg.Done()
+            for j := 0; j < 2000; j++ {
+                chunk, err := slabPool.Get(1 + (id*131+j*17)%1024)
+                if err != nil {
+                    t.Errorf("unexpected error: %s", err)
+                    return
+                }
+                chunk[0] = byte(j)
+                slabPool.IncRef(chunk)
+                slabPool.DecRef(chunk)
+                if err := slabPool.Put(chunk); err != nil {
+                    t.Errorf("unexpected error: %s", err)
+                    return
+                }
+            }
+        }(i)
+    }
+    wg.Wait()
+
+    // all chunks should be released
+    for _, slabClass := range slabPool.slabClasses {
+        if !slabClass.listEmpty(SLAB_USE) || !slabClass.listEmpty(SLAB_FULL) {
+            t.Errorf("all slabs should be free, chunkSize %d", slabClass.chunkSize)
+        }
+    }
+}
+
+func TestConcurrentSharedChunk(t *testing.T) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 64, 1024, 2, &Options{Concurrent: true})
+    chunk, _ := slabPool.Get(1024)
+    slab, chunkIndex, _ := slabPool.locate(chunk)
+
+    // share chunk between goroutines
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        slabPool.IncRef(chunk)
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for j := 0; j < 1000; j++ {
+                slabPool.IncRef(chunk)
+                slabPool.DecRef(chunk)
+            }
+            slabPool.Put(chunk)
+        }()
+    }
+    wg.Wait()
+
+    if slab.chunkInfo[chunkIndex].refs != 1 || slab.countFree != slab.countChunk-1 {
+        t.Errorf("chunk should be referenced only once")
+    }
+
+    // drop the last reference
+    slabPool.Put(chunk)
+    if slab.chunkInfo[chunkIndex].refs != 0 || slab.countFree != slab.countChunk {
+        t.Errorf("chunk should be released")
+    }
+}
+
 func BenchmarkIncAndDecRef(b *testing.B) {
     slabPool, _ := CreateSlabPool(4096, 64, 1024, 2)
     chunk, _ := slabPool.Get(128)
@@ -235,3 +351,112 @@ func benchmarkGetAndPutSize(b *testing.B, slabPool *SlabPool, size int) {
         slabPool.Put(chunk)
     }
 }
+
+func TestWrongReference(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 1024, 1024, 2)
+    chunk1, _ := slabPool.Get(1024)
+    chunk2, _ := slabPool.Get(1024)
+    slab, _, _ := slabPool.locate(chunk1)
+
+    // double free
+    slabPool.Put(chunk1)
+    if err := slabPool.Put(chunk1); err != ErrDoubleFree {
+        t.Errorf("should return ErrDoubleFree, got %v", err)
+    }
+    if slab.countFree != 3 {
+        t.Errorf("count of free chunks should not be changed")
+    }
+
+    // increase reference on chunk freed
+    if err := slabPool.IncRef(chunk1); err != ErrChunkFreed {
+        t.Errorf("should return ErrChunkFreed, got %v", err)
+    }
+
+    // free chunk never allocated
+    chunk3 := slab.memory[2048:3072]
+    if err := slabPool.Put(chunk3); err != ErrNotAllocated {
+        t.Errorf("should return ErrNotAllocated, got %v", err)
+    }
+    if err := slabPool.IncRef(chunk3); err != ErrNotAllocated {
+        t.Errorf("should return ErrNotAllocated, got %v", err)
+    }
+
+    // foreign chunk
+    if err := slabPool.Put(make([]byte, 100)); !errors.Is(err, ErrInvalidChunk) {
+        t.Errorf("should return ErrInvalidChunk, got %v", err)
+    }
+
+    // free list is not corrupted
+    if err := slabPool.Put(chunk2); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    if slab.countFree != 4 || slab.status() != SLAB_FREE {
+        t.Errorf("all chunks should be free")
+    }
+}
+
+func TestCreateSlabPoolWithClasses(t *testing.T) {
+    classes := []ClassConfig{{ChunkSize: 72}, {ChunkSize: 200},
+                             {ChunkSize: 1500, SlabSize: 15000}}
+    slabPool, err := CreateSlabPoolWithClasses(4096, classes, nil)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if len(slabPool.slabClasses) != 3 {
+        t.Fatalf("should have 3 slab classes")
+    }
+    for i, size := range []int{72, 200, 1500} {
+        if slabPool.slabClasses[i].chunkSize != size {
+            t.Errorf("chunk size of class %d should be %d", i, size)
+        }
+    }
+    if slabPool.slabClasses[0].slabSize != 4096 || slabPool.slabClasses[2].slabSize != 15000 {
+        t.Errorf("wrong slab sizes")
+    }
+
+    // allocate chunks from classes
+    for _, size := range []int{1, 72, 73, 200, 201, 1500} {
+        chunk, err := slabPool.Get(size)
+        if err != nil || len(chunk) != size {
+            t.Errorf("should allocate chunk of size %d: %v", size, err)
+        }
+        if err := slabPool.Put(chunk); err != nil {
+            t.Errorf("unexpected error: %s", err)
+        }
+    }
+    if _, err := slabPool.Get(1501); err == nil {
+        t.Errorf("should fail to allocate chunk larger than all classes")
+    }
+    slab, _, _ := slabPool.locate(mustGet(t, slabPool, 1500))
+    if slab.countChunk != 10 {
+        t.Errorf("slab of 15000 bytes should have 10 chunks, got %d", slab.countChunk)
+    }
+
+    // wrong classes
+    wrongClasses := [][]ClassConfig{
+        nil,
+        {{ChunkSize: 0}},
+        {{ChunkSize: 200}, {ChunkSize: 72}},
+        {{ChunkSize: 72}, {ChunkSize: 72}},
+        {{ChunkSize: 5000}},
+        {{ChunkSize: 1500, SlabSize: 1000}},
+    }
+    for _, classes := range wrongClasses {
+        if _, err := CreateSlabPoolWithClasses(4096, classes, nil); err == nil {
+            t.Errorf("wrong classes should be rejected: %v", classes)
+        }
+    }
+    options := &Options{ArenaSize: 1 << 20, ArenaEager: true}
+    if _, err := CreateSlabPoolWithClasses(4096, classes, options); err == nil {
+        t.Errorf("ArenaEager with different slab sizes should be rejected")
+    }
+}
+
+// allocate chunk, fail the test if error
+func mustGet(t *testing.T, slabPool *SlabPool, size int) []byte {
+    chunk, err := slabPool.Get(size)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    return chunk
+}
@@ -0,0 +1,76 @@
+/* slab_shrink.go - background release of free slabs */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, reclaim free slabs for other slab classes
+2026/10/17, by agent, reclaim free slabs for large chunks
+*/
+/*
+DESCRIPTION
+    A slab which stays in SLAB_FREE list longer than IdleTimeout is
+    released by a background goroutine, while at most KeepFree free slabs
+    are kept in each SlabClass for next allocations.
+
+    When a SlabClass hits MaxBytes while other SlabClasses hold free slabs,
+    the free slabs are released by reclaim() for the memory, so the budget
+    is never locked up by free slabs.
+*/
+package slab_pool
+
+import (
+    "time"
+)
+
+// release idle slabs periodically, until slab pool is closed
+func (sp *SlabPool) shrinkLoop(policy ShrinkPolicy) {
+    interval := policy.Interval
+    if interval <= 0 {
+        interval = policy.IdleTimeout / 2
+    }
+    if interval <= 0 {
+        interval = time.Second
+    }
+
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-sp.closeChan:
+            return
+        case now := <-ticker.C:
+            sp.shrinkIdle(policy.KeepFree, now.Add(-policy.IdleTimeout))
+        }
+    }
+}
+
+// release slabs which become free before 'idleBefore' (zero for all)
+func (sp *SlabPool) shrinkIdle(keepFree int, idleBefore time.Time) int {
+    count := 0
+    for _, slabClass := range sp.slabClasses {
+        count += slabClass.slabShrink(keepFree, idleBefore)
+    }
+    return count
+}
+
+// release free slabs of slab classes other than 'except' (nil for none),
+// until memory limit has room for 'need' bytes. Return true if slabs are
+// released and the room is made (so allocation should be tried again)
+func (sp *SlabPool) reclaim(except *SlabClass, need int64) bool {
+    if sp.limit.maxBytes <= 0 || sp.limit.fits(need) {
+        return false
+    }
+
+    count := 0
+    for _, slabClass := range sp.slabClasses {
+        if slabClass == except {
+            continue
+        }
+        count += slabClass.slabReclaim(sp.limit.used() + need - sp.limit.maxBytes)
+        if sp.limit.fits(need) {
+            break
+        }
+    }
+    return count > 0 && sp.limit.fits(need)
+}
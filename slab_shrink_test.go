@@ -0,0 +1,60 @@
+/* slab_shrink_test.go - unit test for slab_shrink.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "testing"
+    "time"
+)
+
+func TestShrinkPolicy(t *testing.T) {
+    policy := &ShrinkPolicy{KeepFree: 1, IdleTimeout: 20 * time.Millisecond,
+                            Interval: 5 * time.Millisecond}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 4096, 4096, 2,
+                                             &Options{ShrinkPolicy: policy})
+    defer slabPool.Close()
+    slabClass := slabPool.slabClasses[0]
+
+    // three free slabs
+    chunks := make([][]byte, 3)
+    for i := range chunks {
+        chunks[i], _ = slabPool.Get(4096)
+    }
+    for _, chunk := range chunks {
+        slabPool.Put(chunk)
+    }
+
+    // wait for idle slabs released
+    deadline := time.Now().Add(2 * time.Second)
+    for time.Now().Before(deadline) {
+        slabClass.lock()
+        count := len(slabClass.slabs)
+        slabClass.unlock()
+        if count == 1 {
+            return
+        }
+        time.Sleep(5 * time.Millisecond)
+    }
+    t.Errorf("idle slabs should be released")
+}
+
+func TestShrinkIdle(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 4096, 4096, 2)
+    chunk, _ := slabPool.Get(4096)
+    slabPool.Put(chunk)
+
+    // slab is not idle long enough
+    if count := slabPool.shrinkIdle(0, time.Now().Add(-time.Hour)); count != 0 {
+        t.Errorf("no slab should be released, got %d", count)
+    }
+    if count := slabPool.shrinkIdle(0, time.Now().Add(time.Second)); count != 1 {
+        t.Errorf("1 slab should be released, got %d", count)
+    }
+}
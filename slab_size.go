@@ -0,0 +1,84 @@
+/* slab_size.go - slab size of each slab class */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, round slab memory up to huge pages
+*/
+/*
+DESCRIPTION
+    Chunks of a slab class seldom fill the slab exactly, and the tail of
+    slab is wasted. E.g. chunk size 1500 in a 4096 bytes slab fits only 2
+    chunks, and leaves 1096 bytes unused.
+
+    With Options.SlabWaste, slab size of each slab class is chosen so that
+    the tail waste is no more than SlabWaste of slab memory. Slab memory
+    (including footer) is a multiple of SLAB_SIZE_UNIT, from the smallest
+    one holding slabSize of pool, and grows up to SLAB_SIZE_SCALE_MAX times.
+    If the target is not met, the slab size with least waste is used.
+
+    With Options.HugePages (not in arena mode), slab memory of each slab
+    class is rounded up to a multiple of HUGE_PAGE_SIZE, since huge pages
+    could not back a smaller mapping.
+*/
+package slab_pool
+
+const (
+    SLAB_SIZE_UNIT      = 4096 // unit of slab memory with automatic slab size
+    SLAB_SIZE_SCALE_MAX = 16   // max times of slab memory grows
+)
+
+// resolve slab size of each slab class
+func (sp *SlabPool) resolveSlabSizes(classes []ClassConfig) []ClassConfig {
+    configs := make([]ClassConfig, len(classes))
+    for i, class := range classes {
+        configs[i] = class
+        if class.SlabSize > 0 {
+            continue
+        }
+        if sp.options.SlabWaste > 0 {
+            chunkStride := class.ChunkSize
+            if sp.options.Debug {
+                chunkStride += CHUNK_CANARY_LEN
+            }
+            configs[i].SlabSize = autoSlabSize(chunkStride, sp.slabSize, sp.options.SlabWaste)
+        } else {
+            configs[i].SlabSize = sp.slabSize
+        }
+    }
+    if sp.options.HugePages && sp.options.ArenaSize == 0 {
+        for i := range configs {
+            configs[i].SlabSize = hugePageSlabSize(configs[i].SlabSize)
+        }
+    }
+    return configs
+}
+
+// round slab memory of 'slabSize' up to a multiple of HUGE_PAGE_SIZE
+func hugePageSlabSize(slabSize int) int {
+    pages := (slabSize + SLAB_FOOTER_LEN + HUGE_PAGE_SIZE - 1) / HUGE_PAGE_SIZE
+    return pages*HUGE_PAGE_SIZE - SLAB_FOOTER_LEN
+}
+
+// choose slab size for chunks of 'chunkStride' bytes, so that the tail
+// waste is no more than 'waste' of slab memory
+func autoSlabSize(chunkStride int, slabSize int, waste float64) int {
+    if slabSize < chunkStride {
+        slabSize = chunkStride
+    }
+    units := (slabSize + SLAB_FOOTER_LEN + SLAB_SIZE_UNIT - 1) / SLAB_SIZE_UNIT
+
+    best, bestWaste := 0, 1.0
+    for i := units; i <= units*SLAB_SIZE_SCALE_MAX; i++ {
+        memSize := i * SLAB_SIZE_UNIT
+        size := memSize - SLAB_FOOTER_LEN
+        w := float64(memSize-size/chunkStride*chunkStride) / float64(memSize)
+        if w <= waste {
+            return size
+        }
+        if w < bestWaste {
+            best, bestWaste = size, w
+        }
+    }
+    return best
+}
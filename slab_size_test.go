@@ -0,0 +1,78 @@
+/* slab_size_test.go - unit test for slab_size.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "testing"
+)
+
+func TestAutoSlabSize(t *testing.T) {
+    // 8 chunks in 3 units, waste 288 bytes
+    if size := autoSlabSize(1500, 4096, 0.05); size != 3*SLAB_SIZE_UNIT-SLAB_FOOTER_LEN {
+        t.Errorf("wrong slab size for chunk 1500: %d", size)
+    }
+    // 511 chunks in 2 units
+    if size := autoSlabSize(16, 4096, 0.05); size != 2*SLAB_SIZE_UNIT-SLAB_FOOTER_LEN {
+        t.Errorf("wrong slab size for chunk 16: %d", size)
+    }
+    // slab holds at least one chunk
+    if size := autoSlabSize(10000, 4096, 0.05); size < 10000 {
+        t.Errorf("slab should hold chunk 10000: %d", size)
+    }
+
+    // target not met, slab size with least waste is used
+    size := autoSlabSize(1500, 4096, 0.0001)
+    if size > 32*SLAB_SIZE_UNIT || (size+SLAB_FOOTER_LEN)%SLAB_SIZE_UNIT != 0 {
+        t.Errorf("wrong slab size: %d", size)
+    }
+    for i := 2; i <= 32; i++ {
+        s := i*SLAB_SIZE_UNIT - SLAB_FOOTER_LEN
+        if s%1500+SLAB_FOOTER_LEN < size%1500+SLAB_FOOTER_LEN {
+            t.Errorf("slab size %d wastes less than %d", s, size)
+        }
+    }
+}
+
+func TestSlabWaste(t *testing.T) {
+    classes := []ClassConfig{{ChunkSize: 72}, {ChunkSize: 1500}, {ChunkSize: 3000, SlabSize: 6000}}
+    slabPool, err := CreateSlabPoolWithClasses(4096, classes, &Options{SlabWaste: 0.05})
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    stats := slabPool.Stats()
+    for i, c := range stats.Classes {
+        waste := (c.SlabSize + SLAB_FOOTER_LEN) - c.SlabSize/c.ChunkSize*c.ChunkSize
+        if i < 2 && float64(waste) > 0.05*float64(c.SlabSize+SLAB_FOOTER_LEN) {
+            t.Errorf("waste of class %d should be no more than 5%%: %d", i, waste)
+        }
+    }
+    if stats.Classes[1].SlabSize != 3*SLAB_SIZE_UNIT-SLAB_FOOTER_LEN {
+        t.Errorf("wrong slab size: %d", stats.Classes[1].SlabSize)
+    }
+    if stats.Classes[2].SlabSize != 6000 {
+        t.Errorf("slab size given should be kept")
+    }
+
+    // chunks in slab of automatic size
+    chunk, _ := slabPool.Get(1500)
+    slab, _, _ := slabPool.locate(chunk)
+    if slab.countChunk != 8 {
+        t.Errorf("slab should have 8 chunks, got %d", slab.countChunk)
+    }
+
+    // factor pool
+    slabPool, _ = CreateSlabPoolWithOptions(4096, 128, 1024, 2, &Options{SlabWaste: 0.05})
+    if slabPool.slabClasses[0].slabSize != 2*SLAB_SIZE_UNIT-SLAB_FOOTER_LEN {
+        t.Errorf("wrong slab size: %d", slabPool.slabClasses[0].slabSize)
+    }
+    if _, err := CreateSlabPoolWithOptions(4096, 128, 1024, 2, &Options{SlabWaste: 1}); err == nil {
+        t.Errorf("wrong SlabWaste should be rejected")
+    }
+}
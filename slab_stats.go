@@ -0,0 +1,194 @@
+/* slab_stats.go - statistics of SlabPool */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+2026/10/17, by agent, add statistics of large chunks
+2026/10/17, by agent, count chunks resized by Grow()
+*/
+/*
+DESCRIPTION
+    Each SlabClass keeps cumulative counters, and figures of chunks in use.
+    They are updated atomically, and the count of slabs in each slab list
+    is maintained by listAdd()/listRemove(). So Stats() only holds the lock
+    of each SlabClass for copying a few numbers, and is cheap enough to be
+    polled frequently.
+
+    Chunks from Go heap (EXHAUST_HEAP policy) are not in any SlabClass, and
+    only counted by Stats.HeapAllocs. Large chunks (Options.LargeAlloc) are
+    not in any SlabClass either, and counted by Stats.Large* only.
+
+Usage:
+    stats := slabPool.Stats()
+    for _, c := range stats.Classes {
+        log.Printf("chunk size %d: %d slabs, %d chunks in use",
+                   c.ChunkSize, c.Slabs, c.ChunksInUse)
+    }
+*/
+package slab_pool
+
+import (
+    "sync/atomic"
+)
+
+// counters of SlabClass (accessed atomically)
+type classCounters struct {
+    gets           uint64 // chunks allocated
+    puts           uint64 // Put() on chunks
+    incRefs        uint64 // IncRef() on chunks
+    decRefs        uint64 // DecRef() on chunks
+    slabAllocs     uint64 // slabs allocated
+    slabReleases   uint64 // slabs released
+
+    chunksInUse    int64  // chunks allocated and not released
+    bytesRequested int64  // bytes requested by chunks in use
+}
+
+// ClassStats is statistics of a SlabClass
+type ClassStats struct {
+    ChunkSize      int    // chunk size
+    SlabSize       int    // slab size (without footer)
+
+    Slabs          int    // count of slabs
+    FreeSlabs      int    // count of slabs in SLAB_FREE list
+    UseSlabs       int    // count of slabs in SLAB_USE list
+    FullSlabs      int    // count of slabs in SLAB_FULL list
+
+    ChunksInUse    int64  // chunks allocated and not released
+    BytesRequested int64  // bytes requested by Get() for chunks in use
+    BytesInUse     int64  // bytes handed out for chunks in use
+                          // (ChunksInUse * ChunkSize)
+
+    Gets           uint64 // cumulative count of chunks allocated
+    Puts           uint64 // cumulative count of Put()
+    IncRefs        uint64 // cumulative count of IncRef()
+    DecRefs        uint64 // cumulative count of DecRef()
+    SlabAllocs     uint64 // cumulative count of slabs allocated
+    SlabReleases   uint64 // cumulative count of slabs released
+}
+
+// Stats is statistics of SlabPool, totals are sums over all slab classes
+type Stats struct {
+    Classes        []ClassStats // statistics of each slab class
+
+    Slabs          int    // count of slabs
+    SlabBytes      int64  // bytes of slab memory (including footers)
+    ChunksInUse    int64  // chunks allocated and not released
+    BytesRequested int64  // bytes requested by Get() for chunks in use
+    BytesInUse     int64  // bytes handed out for chunks in use
+
+    Gets           uint64 // cumulative count of chunks allocated
+    Puts           uint64 // cumulative count of Put()
+    IncRefs        uint64 // cumulative count of IncRef()
+    DecRefs        uint64 // cumulative count of DecRef()
+    SlabAllocs     uint64 // cumulative count of slabs allocated
+    SlabReleases   uint64 // cumulative count of slabs released
+    HeapAllocs     uint64 // cumulative count of chunks from Go heap
+
+    LargeChunks    int64  // large chunks allocated and not released
+    LargeBytes     int64  // bytes of memory of large chunks (including footers)
+    LargeAllocs    uint64 // cumulative count of large chunks allocated
+}
+
+/* Stats - get statistics of slab pool
+ *
+ * Return:
+ *     - statistics of slab pool
+ */
+func (sp *SlabPool) Stats() *Stats {
+    stats := new(Stats)
+    stats.Classes = make([]ClassStats, 0, len(sp.slabClasses))
+    for _, slabClass := range sp.slabClasses {
+        c := slabClass.stats()
+        stats.Classes = append(stats.Classes, c)
+
+        stats.Slabs += c.Slabs
+        stats.SlabBytes += int64(c.Slabs) * slabClass.slabMemSize()
+        stats.ChunksInUse += c.ChunksInUse
+        stats.BytesRequested += c.BytesRequested
+        stats.BytesInUse += c.BytesInUse
+        stats.Gets += c.Gets
+        stats.Puts += c.Puts
+        stats.IncRefs += c.IncRefs
+        stats.DecRefs += c.DecRefs
+        stats.SlabAllocs += c.SlabAllocs
+        stats.SlabReleases += c.SlabReleases
+    }
+    stats.HeapAllocs = atomic.LoadUint64(&sp.heapAllocs)
+    stats.LargeChunks = atomic.LoadInt64(&sp.largeChunks)
+    stats.LargeBytes = atomic.LoadInt64(&sp.largeBytes)
+    stats.LargeAllocs = atomic.LoadUint64(&sp.largeAllocs)
+    return stats
+}
+
+// Fragmentation returns ratio of bytes handed out but not requested
+func (c *ClassStats) Fragmentation() float64 {
+    if c.BytesInUse == 0 {
+        return 0
+    }
+    return 1 - float64(c.BytesRequested)/float64(c.BytesInUse)
+}
+
+// Fragmentation returns ratio of bytes handed out but not requested
+func (s *Stats) Fragmentation() float64 {
+    if s.BytesInUse == 0 {
+        return 0
+    }
+    return 1 - float64(s.BytesRequested)/float64(s.BytesInUse)
+}
+
+// get statistics of slab class
+func (sc *SlabClass) stats() ClassStats {
+    var c ClassStats
+    c.ChunkSize = sc.chunkSize
+    c.SlabSize = sc.slabSize
+
+    sc.lock()
+    c.Slabs = len(sc.slabs)
+    c.FreeSlabs = sc.listLen[SLAB_FREE]
+    c.UseSlabs = sc.listLen[SLAB_USE]
+    c.FullSlabs = sc.listLen[SLAB_FULL]
+    sc.unlock()
+
+    counters := &sc.counters
+    c.ChunksInUse = atomic.LoadInt64(&counters.chunksInUse)
+    c.BytesRequested = atomic.LoadInt64(&counters.bytesRequested)
+    c.BytesInUse = c.ChunksInUse * int64(sc.chunkSize)
+    c.Gets = atomic.LoadUint64(&counters.gets)
+    c.Puts = atomic.LoadUint64(&counters.puts)
+    c.IncRefs = atomic.LoadUint64(&counters.incRefs)
+    c.DecRefs = atomic.LoadUint64(&counters.decRefs)
+    c.SlabAllocs = atomic.LoadUint64(&counters.slabAllocs)
+    c.SlabReleases = atomic.LoadUint64(&counters.slabReleases)
+    return c
+}
+
+// count chunk handed out for 'size' bytes (called by owner of chunk)
+func (sc *SlabClass) chunkGot(slab *Slab, chunkIndex int, size int) {
+    atomic.StoreInt32(&slab.chunkInfo[chunkIndex].size, int32(size))
+    atomic.AddUint64(&sc.counters.gets, 1)
+    atomic.AddInt64(&sc.counters.chunksInUse, 1)
+    atomic.AddInt64(&sc.counters.bytesRequested, int64(size))
+}
+
+// count Put() or DecRef()
+func (sc *SlabClass) countDecRef(put bool) {
+    if put {
+        atomic.AddUint64(&sc.counters.puts, 1)
+    } else {
+        atomic.AddUint64(&sc.counters.decRefs, 1)
+    }
+}
+
+// count chunk resliced to 'size' bytes by Grow()
+func (sc *SlabClass) chunkResized(slab *Slab, chunkIndex int, size int) {
+    old := atomic.SwapInt32(&slab.chunkInfo[chunkIndex].size, int32(size))
+    atomic.AddInt64(&sc.counters.bytesRequested, int64(size)-int64(old))
+}
+
+// count chunk released by the last reference
+func (sc *SlabClass) chunkDropped(slab *Slab, chunkIndex int) {
+    size := atomic.LoadInt32(&slab.chunkInfo[chunkIndex].size)
+    atomic.AddInt64(&sc.counters.chunksInUse, -1)
+    atomic.AddInt64(&sc.counters.bytesRequested, -int64(size))
+}
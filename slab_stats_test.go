@@ -0,0 +1,86 @@
+/* slab_stats_test.go - unit test for slab_stats.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "testing"
+)
+
+func TestStats(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 512, 1024, 2)
+    chunks := make([][]byte, 0)
+    for i := 0; i < 5; i++ {
+        chunk, _ := slabPool.Get(1000)
+        chunks = append(chunks, chunk)
+    }
+    chunk, _ := slabPool.Get(300)
+    slabPool.IncRef(chunk)
+    slabPool.DecRef(chunk)
+    slabPool.Put(chunks[4])
+
+    stats := slabPool.Stats()
+    if len(stats.Classes) != 2 {
+        t.Fatalf("should have 2 slab classes")
+    }
+    c := stats.Classes[1]
+    if c.ChunkSize != 1024 || c.SlabSize != 4096 || c.Slabs != 2 ||
+       c.FullSlabs != 1 || c.FreeSlabs != 1 || c.UseSlabs != 0 {
+        t.Errorf("wrong slabs of class 1: %+v", c)
+    }
+    if c.ChunksInUse != 4 || c.BytesRequested != 4000 || c.BytesInUse != 4096 {
+        t.Errorf("wrong chunks in use of class 1: %+v", c)
+    }
+    if c.Gets != 5 || c.Puts != 1 || c.SlabAllocs != 2 {
+        t.Errorf("wrong counters of class 1: %+v", c)
+    }
+    c = stats.Classes[0]
+    if c.ChunksInUse != 1 || c.BytesRequested != 300 || c.UseSlabs != 1 ||
+       c.IncRefs != 1 || c.DecRefs != 1 || c.Puts != 0 {
+        t.Errorf("wrong stats of class 0: %+v", c)
+    }
+    if stats.Slabs != 3 || stats.SlabBytes != 3*int64(4096+SLAB_FOOTER_LEN) ||
+       stats.ChunksInUse != 5 || stats.Gets != 6 {
+        t.Errorf("wrong totals: %+v", stats)
+    }
+    if f := stats.Fragmentation(); f < 0.06 || f > 0.07 {
+        t.Errorf("fragmentation should be (4608-4300)/4608, got %f", f)
+    }
+
+    // shrink slabs
+    slabPool.Shrink(0)
+    if c := slabPool.Stats().Classes[1]; c.Slabs != 1 || c.SlabReleases != 1 {
+        t.Errorf("should release 1 slab: %+v", c)
+    }
+}
+
+func TestStatsCacheAndHeap(t *testing.T) {
+    options := &Options{MaxSlabsPerClass: 1, ExhaustPolicy: EXHAUST_HEAP}
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 1024, 1024, 2, options)
+    cache := slabPool.NewChunkCache(&CacheOptions{Depth: 4, Batch: 4})
+    chunk, _ := cache.Get(100)
+    stats := slabPool.Stats()
+    if stats.ChunksInUse != 1 || stats.BytesRequested != 100 || stats.Gets != 1 {
+        t.Errorf("chunks in cache should not be in use: %+v", stats)
+    }
+    cache.Put(chunk)
+    stats = slabPool.Stats()
+    if stats.ChunksInUse != 0 || stats.BytesRequested != 0 || stats.Puts != 1 {
+        t.Errorf("chunk put to cache should not be in use: %+v", stats)
+    }
+
+    // chunk from Go heap
+    cache.Flush()
+    for i := 0; i < 5; i++ {
+        slabPool.Get(1000)
+    }
+    if stats = slabPool.Stats(); stats.HeapAllocs != 1 || stats.ChunksInUse != 4 {
+        t.Errorf("should allocate 1 chunk from heap: %+v", stats)
+    }
+}
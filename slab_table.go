@@ -0,0 +1,58 @@
+/* slab_table.go - index from chunks to slabs */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+    All chunks of a slab share the end of slab memory as the end of their
+    capacity, so slabTable indexes slabs by the address of the last byte
+    of slab memory (the last byte of footer). The slab of a chunk is then
+    found in O(1) without reading memory of the chunk, so chunks of
+    released (even unmapped) slabs and foreign chunks are safely rejected.
+
+    Each slab has an unique ID, which is written into its footer together
+    with the magic number of pool, for detecting corrupted footers.
+*/
+package slab_pool
+
+import (
+    "sync"
+    "sync/atomic"
+)
+
+type slabTable struct {
+    lastID uint64   // last slab ID allocated (accessed atomically)
+    slabs  sync.Map // last byte of slab memory => *Slab
+}
+
+func newSlabTable() *slabTable {
+    return new(slabTable)
+}
+
+// add slab to table, and allocate slab ID for it
+func (t *slabTable) register(s *Slab) {
+    s.id = atomic.AddUint64(&t.lastID, 1)
+    s.initFooter()
+    t.slabs.Store(memoryKey(s.memory), s)
+}
+
+// remove slab from table
+func (t *slabTable) unregister(s *Slab) {
+    t.slabs.Delete(memoryKey(s.memory))
+}
+
+// find slab of chunk, return nil if not found
+func (t *slabTable) lookup(chunk []byte) *Slab {
+    slab, ok := t.slabs.Load(memoryKey(chunk))
+    if !ok {
+        return nil
+    }
+    return slab.(*Slab)
+}
+
+// key for memory in table: address of the last byte of capacity
+func memoryKey(memory []byte) *byte {
+    return &memory[:cap(memory)][cap(memory)-1]
+}
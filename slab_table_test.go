@@ -0,0 +1,70 @@
+/* slab_table_test.go - unit test for slab_table.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "testing"
+)
+
+func TestSlabTable(t *testing.T) {
+    table := newSlabTable()
+    slab1 := NewSlab(nil, 4096, 1024, 201412)
+    slab2 := NewSlab(nil, 4096, 1024, 201412)
+    table.register(slab1)
+    table.register(slab2)
+    if slab1.id == slab2.id || slab1.id == 0 {
+        t.Errorf("slab ID should be unique")
+    }
+    if !slab1.checkFooter() || !slab2.checkFooter() {
+        t.Errorf("footer of slab should be valid")
+    }
+
+    // lookup by chunks
+    for i := 0; i < 4; i++ {
+        if table.lookup(slab1.chunkAlloc()) != slab1 {
+            t.Errorf("should return slab1 for its chunks")
+        }
+    }
+    if table.lookup(slab2.chunkAlloc()[:10]) != slab2 {
+        t.Errorf("should return slab2 for its chunks")
+    }
+    if table.lookup(make([]byte, 100)) != nil {
+        t.Errorf("should return nil for foreign chunk")
+    }
+
+    // lookup after unregister
+    chunk := slab1.chunk(0)
+    table.unregister(slab1)
+    if table.lookup(chunk) != nil {
+        t.Errorf("should return nil after slab removed")
+    }
+}
+
+func TestLocateChunk(t *testing.T) {
+    slabPool, _ := CreateSlabPool(4096, 1024, 1024, 2)
+    chunk, _ := slabPool.Get(1024)
+
+    // chunk not at boundary of chunks
+    if _, _, err := slabPool.locate(chunk[100:]); err == nil {
+        t.Errorf("should return error for chunk not at boundary")
+    }
+
+    // corrupted footer
+    slab, _, _ := slabPool.locate(chunk)
+    footer := slab.memory[slab.slabSize:]
+    footer[15]++
+    if _, _, err := slabPool.locate(chunk); err == nil {
+        t.Errorf("should return error for corrupted footer")
+    }
+    footer[15]--
+    if _, _, err := slabPool.locate(chunk); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+}
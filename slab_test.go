@@ -27,3 +27,20 @@ func TestChunkAlloc(t *testing.T) {
         t.Errorf("there is no more chunk, should return nil")
     }
 }
+
+func TestSlabRelease(t *testing.T) {
+    slab := NewSlab(nil, 4096, 2048, 201412)
+    chunk := slab.chunkAlloc()
+
+    // a stale chunk located concurrently fails footer check, not panics
+    memory := slab.release()
+    if len(memory) != 4096+SLAB_FOOTER_LEN {
+        t.Errorf("should return slab memory")
+    }
+    if slab.checkFooter() {
+        t.Errorf("footer should be cleared")
+    }
+    if len(slab.chunkInfo) != 2 || slab.chunkInfo[0].getRef() != 1 || len(chunk) != 2048 {
+        t.Errorf("chunk info should be kept")
+    }
+}
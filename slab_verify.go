@@ -0,0 +1,207 @@
+/* slab_verify.go - integrity checker for SlabPool */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+    Verify() walks every SlabClass of pool, and checks:
+    - slabLists are well-formed doubly-linked lists, and each slab is
+      in the list matching its status()
+    - chunk free list of each slab has exactly countFree chunks, and
+      chunks in free list are not referenced
+    - footer of each slab carries the right magic and slab ID, and the
+      slab is registered in slabTable
+    - free chunks are still poisoned (debug mode only)
+
+    Each SlabClass is locked while it is checked, so Verify() could be
+    called periodically on a pool in use.
+
+Usage:
+    if report := slabPool.Verify(); !report.OK() {
+        log.Printf("slab pool corrupted:\n%s", report)
+    }
+*/
+package slab_pool
+
+import (
+    "bytes"
+    "fmt"
+)
+
+// Violation is a broken invariant found by Verify()
+type Violation struct {
+    Class   int    // index of slab class
+    Slab    int    // slab index in slab class (-1 for slab class)
+    Chunk   int    // chunk index in slab (-1 for slab or slab class)
+    Problem string // description of violation
+}
+
+// VerifyReport is the result of Verify()
+type VerifyReport struct {
+    Slabs      int         // count of slabs checked
+    Chunks     int         // count of chunks checked
+    Violations []Violation // violations found
+}
+
+/* Verify - check integrity of slab pool
+ *
+ * Return:
+ *     - report of violations found
+ */
+func (sp *SlabPool) Verify() *VerifyReport {
+    report := new(VerifyReport)
+    for _, slabClass := range sp.slabClasses {
+        slabClass.lock()
+        slabClass.verify(report)
+        slabClass.unlock()
+    }
+    return report
+}
+
+// OK returns true if no violation is found
+func (r *VerifyReport) OK() bool {
+    return len(r.Violations) == 0
+}
+
+// String returns violations, one per line
+func (r *VerifyReport) String() string {
+    var buf bytes.Buffer
+    fmt.Fprintf(&buf, "%d slabs, %d chunks, %d violations\n",
+                r.Slabs, r.Chunks, len(r.Violations))
+    for _, v := range r.Violations {
+        fmt.Fprintf(&buf, "%s\n", v)
+    }
+    return buf.String()
+}
+
+func (v Violation) String() string {
+    if v.Slab < 0 {
+        return fmt.Sprintf("class %d: %s", v.Class, v.Problem)
+    }
+    if v.Chunk < 0 {
+        return fmt.Sprintf("class %d, slab %d: %s", v.Class, v.Slab, v.Problem)
+    }
+    return fmt.Sprintf("class %d, slab %d, chunk %d: %s",
+                       v.Class, v.Slab, v.Chunk, v.Problem)
+}
+
+// add violation to report
+func (r *VerifyReport) add(class int, slab int, chunk int, format string,
+    args ...interface{}) {
+    r.Violations = append(r.Violations, Violation{
+        Class:   class,
+        Slab:    slab,
+        Chunk:   chunk,
+        Problem: fmt.Sprintf(format, args...),
+    })
+}
+
+// check slab class (caller should hold the lock)
+func (sc *SlabClass) verify(r *VerifyReport) {
+    // each slab should be in exactly one slab list
+    listed := make([]bool, len(sc.slabs))
+    for whichList := SLAB_FREE; whichList <= SLAB_FULL; whichList++ {
+        sc.verifyList(r, whichList, listed)
+    }
+    for i, slab := range sc.slabs {
+        if !listed[i] {
+            r.add(sc.index, i, -1, "not in any slab list")
+        }
+        if slab.index != i {
+            r.add(sc.index, i, -1, "wrong slab index %d", slab.index)
+        }
+        if slab.slabClass != sc {
+            r.add(sc.index, i, -1, "wrong slab class")
+        }
+        r.Slabs++
+        slab.verify(r)
+    }
+}
+
+// check slab list 'whichList' (caller should hold the lock)
+func (sc *SlabClass) verifyList(r *VerifyReport, whichList int, listed []bool) {
+    prev := -1
+    for node := sc.slabLists[whichList]; node >= 0; node = sc.slabs[node].next {
+        if node >= len(sc.slabs) {
+            r.add(sc.index, -1, -1, "slab list %d: node %d out of range",
+                  whichList, node)
+            return
+        }
+        if listed[node] {
+            // stop at loop, or node shared by lists
+            r.add(sc.index, node, -1, "slab list %d: slab linked twice", whichList)
+            return
+        }
+        listed[node] = true
+
+        slab := sc.slabs[node]
+        if slab.prev != prev {
+            r.add(sc.index, node, -1, "slab list %d: prev is %d, should be %d",
+                  whichList, slab.prev, prev)
+        }
+        if slab.whichList != whichList {
+            r.add(sc.index, node, -1, "in slab list %d, but whichList is %d",
+                  whichList, slab.whichList)
+        }
+        if status := slab.status(); status != whichList {
+            r.add(sc.index, node, -1, "in slab list %d, but status is %d",
+                  whichList, status)
+        }
+        prev = node
+    }
+}
+
+// check slab (caller should hold the lock of slab class)
+func (s *Slab) verify(r *VerifyReport) {
+    class := s.slabClass.index
+    if !s.checkFooter() {
+        r.add(class, s.index, -1, "footer corrupted")
+    }
+    if s.slabClass.table.lookup(s.memory) != s {
+        r.add(class, s.index, -1, "not registered in slab table")
+    }
+    if s.countFree < 0 || s.countFree > s.countChunk {
+        r.add(class, s.index, -1, "wrong countFree %d", s.countFree)
+    }
+
+    // walk chunk free list
+    free := make([]bool, s.countChunk)
+    count := 0
+    for node := s.chunkFree; node >= 0; node = s.chunkInfo[node].next {
+        if node >= s.countChunk {
+            r.add(class, s.index, -1, "chunk free list: node %d out of range", node)
+            break
+        }
+        if free[node] {
+            r.add(class, s.index, node, "chunk free list: chunk linked twice")
+            break
+        }
+        free[node] = true
+        count++
+    }
+    if count != s.countFree {
+        r.add(class, s.index, -1, "%d chunks in free list, but countFree is %d",
+              count, s.countFree)
+    }
+
+    // check chunks
+    for i := range s.chunkInfo {
+        r.Chunks++
+        refs := s.chunkInfo[i].getRef()
+        if free[i] {
+            if refs > 0 {
+                r.add(class, s.index, i, "chunk in free list has %d refs", refs)
+            }
+            if s.debug {
+                if err := s.checkPoison(i); err != nil {
+                    r.add(class, s.index, i, "%s", err)
+                }
+            }
+        } else if refs == CHUNK_UNUSED {
+            // chunks with zero refs are owned by ChunkCache or quarantined
+            r.add(class, s.index, i, "chunk never allocated is out of free list")
+        }
+    }
+}
@@ -0,0 +1,129 @@
+/* slab_verify_test.go - unit test for slab_verify.go */
+/*
+modification history
+--------------------
+2026/10/17, by agent, create
+*/
+/*
+DESCRIPTION
+*/
+package slab_pool
+
+import (
+    "testing"
+)
+
+// create slab pool with slabs in all slab lists
+func verifyTestPool(t *testing.T, options *Options) (*SlabPool, [][]byte) {
+    slabPool, _ := CreateSlabPoolWithOptions(4096, 1024, 1024, 2, options)
+    chunks := make([][]byte, 0)
+    for i := 0; i < 12; i++ {
+        chunk, _ := slabPool.Get(1000)
+        chunks = append(chunks, chunk)
+    }
+    // a full slab, a slab in use and a free slab
+    for _, chunk := range chunks[4:] {
+        slabPool.Put(chunk)
+    }
+    slabPool.Get(1000)
+    if report := slabPool.Verify(); !report.OK() {
+        t.Fatalf("pool should be verified:\n%s", report)
+    }
+    return slabPool, chunks[:4]
+}
+
+func TestVerify(t *testing.T) {
+    slabPool, _ := verifyTestPool(t, nil)
+    report := slabPool.Verify()
+    if report.Slabs != 3 || report.Chunks != 12 {
+        t.Errorf("should check 3 slabs and 12 chunks, got %d and %d",
+                 report.Slabs, report.Chunks)
+    }
+
+    // chunks in chunk cache
+    cache := slabPool.NewChunkCache(nil)
+    chunk, _ := cache.Get(1000)
+    cache.Put(chunk)
+    if report := slabPool.Verify(); !report.OK() {
+        t.Errorf("pool with chunk cache should be verified:\n%s", report)
+    }
+
+    // debug mode
+    slabPool, _ = verifyTestPool(t, &Options{Debug: true})
+    sc := slabPool.slabClasses[0]
+    slab := sc.slabs[sc.slabLists[SLAB_FREE]]
+    slab.chunk(1)[0] = 0
+    report = slabPool.Verify()
+    if len(report.Violations) != 1 || report.Violations[0].Chunk != 1 {
+        t.Errorf("should report write after free:\n%s", report)
+    }
+}
+
+func TestVerifySlabList(t *testing.T) {
+    slabPool, _ := verifyTestPool(t, nil)
+    sc := slabPool.slabClasses[0]
+
+    // slab in wrong list
+    sc.slabs[0].whichList = SLAB_USE
+    report := slabPool.Verify()
+    if len(report.Violations) != 1 || report.Violations[0].Slab != 0 {
+        t.Errorf("should report wrong whichList:\n%s", report)
+    }
+    sc.slabs[0].whichList = SLAB_FULL
+
+    // broken prev link
+    sc.slabs[0].prev = 2
+    report = slabPool.Verify()
+    if len(report.Violations) != 1 || report.Violations[0].Slab != 0 {
+        t.Errorf("should report wrong prev:\n%s", report)
+    }
+    sc.slabs[0].prev = -1
+
+    // slab lost from lists
+    free := sc.slabLists[SLAB_FREE]
+    sc.slabLists[SLAB_FREE] = -1
+    report = slabPool.Verify()
+    if len(report.Violations) != 1 || report.Violations[0].Slab != free {
+        t.Errorf("should report slab not in lists:\n%s", report)
+    }
+}
+
+func TestVerifySlab(t *testing.T) {
+    slabPool, chunks := verifyTestPool(t, nil)
+    sc := slabPool.slabClasses[0]
+    slab := sc.slabs[sc.slabLists[SLAB_USE]]
+
+    // wrong countFree
+    slab.countFree--
+    report := slabPool.Verify()
+    if report.OK() {
+        t.Errorf("should report wrong countFree")
+    }
+    slab.countFree++
+
+    // referenced chunk in free list
+    slab.chunkInfo[slab.chunkFree].setRef(1)
+    report = slabPool.Verify()
+    if len(report.Violations) != 1 || report.Violations[0].Chunk != slab.chunkFree {
+        t.Errorf("should report referenced chunk in free list:\n%s", report)
+    }
+    slab.chunkInfo[slab.chunkFree].setRef(0)
+
+    // loop in free list
+    next := slab.chunkInfo[slab.chunkFree].next
+    nextNext := slab.chunkInfo[next].next
+    slab.chunkInfo[next].next = slab.chunkFree
+    report = slabPool.Verify()
+    if report.OK() {
+        t.Errorf("should report loop in free list")
+    }
+    slab.chunkInfo[next].next = nextNext
+
+    // corrupted footer
+    full := chunks[0][:cap(chunks[0])]
+    full[len(full)-1]++
+    report = slabPool.Verify()
+    if len(report.Violations) != 1 || report.Violations[0].Slab != 0 {
+        t.Errorf("should report corrupted footer:\n%s", report)
+    }
+}